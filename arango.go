@@ -5,9 +5,7 @@ import (
 	"fmt"
 	"github.com/arangodb/go-driver"
 	"github.com/arangodb/go-driver/http"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -22,6 +20,15 @@ type arangoArtifact struct {
 	Description string    `json:"description"`
 	CreateTime  time.Time `json:"create_time"`
 	Item        int       `json:"item"`
+
+	// Extra fields stored as a nested object, the updates leave it as it is.
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// arangoArtifactUpdate is the patch of the updates, the other fields of the document stay as they are.
+type arangoArtifactUpdate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 type arangoEdge struct {
@@ -37,6 +44,17 @@ type arangoEdge struct {
 	Body string `json:"body"`
 }
 
+func (a arangoArtifact) toArtifact() Artifact {
+	return Artifact{
+		Key:         a.Key,
+		Name:        a.Name,
+		Description: a.Description,
+		CreateTime:  a.CreateTime,
+		Item:        a.Item,
+		Payload:     a.Payload,
+	}
+}
+
 func InitArango(endpoint, dbName string) (driver.Database, error) {
 	conn, err := http.NewConnection(http.ConnectionConfig{Endpoints: []string{endpoint}})
 	if err != nil {
@@ -113,69 +131,69 @@ func CreateArangoEdgeCollection(db driver.Database, collection string) error {
 	return nil
 }
 
-func createArangoDocuments(ctx context.Context, db driver.Database, collection string, n int) ([]string, int, error) {
+func newArangoArtifact(artifact Artifact) arangoArtifact {
+	return arangoArtifact{
+		Key:         artifact.Key,
+		Name:        artifact.Name,
+		Description: artifact.Description,
+		CreateTime:  artifact.CreateTime,
+		Item:        artifact.Item,
+		Payload:     artifact.Payload,
+	}
+}
+
+func newArangoEdge(documentCollection string, edge Edge) arangoEdge {
+	return arangoEdge{
+		Key:  edge.Key,
+		From: fmt.Sprintf("%s/%s", documentCollection, edge.From),
+		To:   fmt.Sprintf("%s/%s", documentCollection, edge.To),
+		Body: edge.Body,
+	}
+}
+
+func createArangoDocuments(ctx context.Context, db driver.Database, collection string, artifacts []Artifact) error {
 
 	col, err := db.Collection(ctx, collection)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed getting collection")
+		return errors.Wrap(err, "failed getting collection")
 	}
 
-	var keys []string
-
 	// TODO: Do it in transaction?
 
-	for i := 0; i < n; i++ {
-		artifact := arangoArtifact{
-			Name:        fmt.Sprintf("artifact-%d", i),
-			Description: fmt.Sprintf("description-%d", i),
-			CreateTime:  time.Now(),
-		}
+	for _, artifact := range artifacts {
+		document := newArangoArtifact(artifact)
 
-		meta, err := col.CreateDocument(ctx, &artifact)
+		_, err := col.CreateDocument(ctx, &document)
 		if err != nil {
-			return nil, 0, errors.Wrap(err, "failed creating document")
+			return errors.Wrap(err, "failed creating document")
 		}
-
-		keys = append(keys, meta.Key)
-	}
-
-	count, err := col.Count(ctx)
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed counting documents")
 	}
 
-	return keys, int(count), nil
+	return nil
 }
 
-func CreateBulkArangoDocuments(ctx context.Context, db driver.Database, collection string, n int) ([]string, int, error) {
+func CreateBulkArangoDocuments(ctx context.Context, db driver.Database, collection string, artifacts []Artifact) error {
 
 	col, err := db.Collection(ctx, collection)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed getting collection")
+		return errors.Wrap(err, "failed getting collection")
 	}
 
-	var documents []arangoArtifact
-
-	for i := 0; i < n; i++ {
-		artifact := arangoArtifact{
-			Name:        fmt.Sprintf("artifact-%d", i),
-			Description: fmt.Sprintf("description-%d", i),
-			CreateTime:  time.Now(),
-		}
-		documents = append(documents, artifact)
+	documents := make([]arangoArtifact, len(artifacts))
+	for i, artifact := range artifacts {
+		documents[i] = newArangoArtifact(artifact)
 	}
 
-	metaSlice, _, err := col.CreateDocuments(ctx, documents)
+	_, errs, err := col.CreateDocuments(ctx, documents)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed creating documents")
+		return errors.Wrap(err, "failed creating documents")
 	}
 
-	count, err := col.Count(ctx)
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed counting documents")
+	if err := errs.FirstNonNil(); err != nil {
+		return errors.Wrap(err, "failed creating documents")
 	}
 
-	return metaSlice.Keys(), int(count), nil
+	return nil
 }
 
 func readOneArangoDocument(ctx context.Context, db driver.Database, collection string, key string) error {
@@ -205,29 +223,27 @@ func readBulkArangoDocuments(ctx context.Context, db driver.Database, collection
 	// It is required to allocate the array to the correct size.
 	documents := make([]arangoArtifact, len(keys))
 
-	metas, _, err := col.ReadDocuments(ctx, keys, documents)
+	_, errs, err := col.ReadDocuments(ctx, keys, documents)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed reading documents")
 	}
 
-	return len(metas.Keys()), nil
+	return arangoSucceeded(errs), nil
 }
 
-func updateOneArangoDocument(ctx context.Context, db driver.Database, collection string, key string) error {
+func updateOneArangoDocument(ctx context.Context, db driver.Database, collection string, artifact Artifact) error {
 
 	col, err := db.Collection(ctx, collection)
 	if err != nil {
 		return errors.Wrap(err, "failed getting collection")
 	}
 
-	i := rand.Intn(1000)
-	document := arangoArtifact{
-		Name:        fmt.Sprintf("new-artifact-%d", i),
-		Description: fmt.Sprintf("new-description-%d", i),
-		CreateTime:  time.Now(),
+	document := arangoArtifactUpdate{
+		Name:        artifact.Name,
+		Description: artifact.Description,
 	}
 
-	_, err = col.UpdateDocument(ctx, key, &document)
+	_, err = col.UpdateDocument(ctx, artifact.Key, &document)
 	if err != nil {
 		return errors.Wrap(err, "failed updating document")
 	}
@@ -235,43 +251,52 @@ func updateOneArangoDocument(ctx context.Context, db driver.Database, collection
 	return nil
 }
 
-func updateBulkArangoDocuments(ctx context.Context, db driver.Database, collection string, keys []string) (int, error) {
+func updateBulkArangoDocuments(ctx context.Context, db driver.Database, collection string, artifacts []Artifact) (int, error) {
 
 	col, err := db.Collection(ctx, collection)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed getting collection")
 	}
 
-	var documents []arangoArtifact
-
-	n := len(keys)
-	for i := 0; i < n; i++ {
-		i := rand.Intn(1000)
-		artifact := arangoArtifact{
-			Name:        fmt.Sprintf("new-artifact-%d", i),
-			Description: fmt.Sprintf("new-description-%d", i),
-			CreateTime:  time.Now(),
+	keys := make([]string, len(artifacts))
+	documents := make([]arangoArtifactUpdate, len(artifacts))
+	for i, artifact := range artifacts {
+		keys[i] = artifact.Key
+		documents[i] = arangoArtifactUpdate{
+			Name:        artifact.Name,
+			Description: artifact.Description,
 		}
-		documents = append(documents, artifact)
 	}
 
-	metas, _, err := col.UpdateDocuments(ctx, keys, documents)
+	_, errs, err := col.UpdateDocuments(ctx, keys, documents)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed updating document")
 	}
 
-	return len(metas.Keys()), nil
+	return arangoSucceeded(errs), nil
+}
+
+// arangoSucceeded returns the number of documents of a multi-document operation without an error, the missing
+// ones included in the errors.
+func arangoSucceeded(errs driver.ErrorSlice) int {
+	var count int
+	for _, err := range errs {
+		if err == nil {
+			count++
+		}
+	}
+	return count
 }
 
 func keysToArangoArray(keys []string) string {
 	return "\"" + strings.Join(keys, "\",\"") + "\""
 }
 
-func queryArangoDocuments(ctx context.Context, db driver.Database, collection string, keys []string) (int, error) {
+// countArangoQuery runs the query, reads all returned documents and returns their count.
+func countArangoQuery(ctx context.Context, db driver.Database, queryString string, bindVars map[string]interface{}) (int, error) {
 
-	queryString := fmt.Sprintf("FOR d IN %s FILTER d._key IN [%s] RETURN d", collection, keysToArangoArray(keys))
 	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
+	cursor, err := db.Query(newCTX, queryString, bindVars)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed querying database")
 	}
@@ -294,387 +319,366 @@ func queryArangoDocuments(ctx context.Context, db driver.Database, collection st
 	return int(cursor.Count()), nil
 }
 
-// createArangoConnectPairs creates an N pairs. Pair is a document connected with an edge: Doc1 --> Edge --> Doc2.
-func createArangoConnectedPairs(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, n int) ([]string, []string, int, int, error) {
-
-	// Document handling.
+// readArangoQuery runs the query and reads its first result into the value.
+func readArangoQuery(ctx context.Context, db driver.Database, queryString string, bindVars map[string]interface{}, value interface{}) error {
 
-	documentCol, err := db.Collection(ctx, documentCollection)
+	cursor, err := db.Query(ctx, queryString, bindVars)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
+		return errors.Wrap(err, "failed querying database")
 	}
+	defer cursor.Close()
 
-	var documents []arangoArtifact
-
-	tm := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
-
-	for i := 0; i < n; i++ {
-		artifactFrom := arangoArtifact{
-			Name:        fmt.Sprintf("artifact-from-%d", i),
-			Description: fmt.Sprintf("description-%d", i),
-			CreateTime:  tm,
-		}
-
-		artifactTo := arangoArtifact{
-			Name:        fmt.Sprintf("artifact-to-%d", i),
-			Description: fmt.Sprintf("description-%d", i),
-			CreateTime:  tm,
-		}
-
-		documents = append(documents, artifactFrom, artifactTo)
-		tm = tm.AddDate(0, 0, 1)
-	}
+	_, err = cursor.ReadDocument(ctx, value)
 
-	documentMetas, _, err := documentCol.CreateDocuments(ctx, documents)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating document")
+	if driver.IsNoMoreDocuments(err) {
+		return errors.New("no document found by query")
 	}
-	documentIDs := documentMetas.IDs()
 
-	documentCount, err := documentCol.Count(ctx)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting documents")
+		return errors.Wrap(err, "failed reading document")
 	}
 
-	// Edge handling.
+	return nil
+}
 
-	edgeCol, err := db.Collection(ctx, edgeCollection)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
-	}
+func queryArangoDocuments(ctx context.Context, db driver.Database, collection string, keys []string) (int, error) {
+	queryString := "FOR d IN @@collection FILTER d._key IN @keys RETURN d"
+	return countArangoQuery(ctx, db, queryString, map[string]interface{}{"@collection": collection, "keys": keys})
+}
 
-	var edges []arangoEdge
+func queryArangoDocumentsInlined(ctx context.Context, db driver.Database, collection string, keys []string) (int, error) {
+	queryString := fmt.Sprintf("FOR d IN %s FILTER d._key IN [%s] RETURN d", collection, keysToArangoArray(keys))
+	return countArangoQuery(ctx, db, queryString, nil)
+}
 
-	for i := 0; i < n; i++ {
+// CreateArangoGraph creates the documents and the edges connecting them. It is used for pairs, chains and
+// neighbours and by the populate command.
+func CreateArangoGraph(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, dataset Dataset) error {
 
-		j := i * 2
+	// Document handling.
 
-		edge := arangoEdge{
-			From: documentIDs[j].String(),
-			To:   documentIDs[j+1].String(),
-			Body: fmt.Sprintf("body-%d", i),
+	if len(dataset.Artifacts) > 0 {
+		if err := CreateBulkArangoDocuments(ctx, db, documentCollection, dataset.Artifacts); err != nil {
+			return err
 		}
+	}
 
-		edges = append(edges, edge)
+	// Edge handling.
+
+	if len(dataset.Edges) == 0 {
+		return nil
 	}
 
-	edgeMetas, _, err := edgeCol.CreateDocuments(ctx, edges)
+	edgeCol, err := db.Collection(ctx, edgeCollection)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating edge")
+		return errors.Wrap(err, "failed getting collection")
 	}
 
-	edgeCount, err := edgeCol.Count(ctx)
+	edges := make([]arangoEdge, len(dataset.Edges))
+	for i, edge := range dataset.Edges {
+		edges[i] = newArangoEdge(documentCollection, edge)
+	}
+
+	_, errs, err := edgeCol.CreateDocuments(ctx, edges)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting edges")
+		return errors.Wrap(err, "failed creating edge")
+	}
+
+	if err := errs.FirstNonNil(); err != nil {
+		return errors.Wrap(err, "failed creating edge")
 	}
 
-	return documentMetas.Keys(), edgeMetas.Keys(), int(documentCount), int(edgeCount), nil
+	return nil
 }
 
 func queryAllArangoPairs(ctx context.Context, db driver.Database, documentCollection, edgeCollection string) (int, error) {
+	queryString := "FOR d IN @@documents FOR v IN OUTBOUND d._id @@edges RETURN v"
+	return countArangoQuery(ctx, db, queryString, map[string]interface{}{"@documents": documentCollection, "@edges": edgeCollection})
+}
 
-	queryString := fmt.Sprintf("FOR d IN %s FOR v IN OUTBOUND d._id %s RETURN v", documentCollection, edgeCollection)
-	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed querying database")
-	}
-	defer cursor.Close()
-
-	for {
-		var document arangoArtifact
-
-		_, err := cursor.ReadDocument(newCTX, &document)
+func queryAllArangoPairsOneYear(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, year int) (int, error) {
+	queryString := "FOR d IN @@documents FILTER d.create_time > @lower && d.create_time < @upper FOR v IN OUTBOUND d._id @@edges RETURN v"
+	bindVars := map[string]interface{}{
+		"@documents": documentCollection,
+		"@edges":     edgeCollection,
+		"lower":      fmt.Sprintf("%d", year),
+		"upper":      fmt.Sprintf("%d", year+1),
+	}
+	return countArangoQuery(ctx, db, queryString, bindVars)
+}
 
-		if driver.IsNoMoreDocuments(err) {
-			break
-		}
+func queryAllArangoPairsOneYearInlined(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, year int) (int, error) {
+	queryString := fmt.Sprintf("FOR d IN %s FILTER d.create_time > '%d' && d.create_time < '%d' FOR v IN OUTBOUND d._id %s RETURN v", documentCollection, year, year+1, edgeCollection)
+	return countArangoQuery(ctx, db, queryString, nil)
+}
 
-		if err != nil {
-			return 0, errors.Wrap(err, "failed reading document")
-		}
-	}
+func queryArangoNeighbourN(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (arangoArtifact, error) {
+	queryString := "FOR v IN @index..@index OUTBOUND @start @@edges RETURN v"
+	bindVars := map[string]interface{}{"index": index, "start": documentCollection + "/" + key, "@edges": edgeCollection}
 
-	return int(cursor.Count()), nil
+	var document arangoArtifact
+	err := readArangoQuery(ctx, db, queryString, bindVars, &document)
+	return document, err
 }
 
-func queryAllArangoPairsOneYear(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, year int) (int, error) {
+func queryArangoNeighbourNInlined(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (arangoArtifact, error) {
+	queryString := fmt.Sprintf("FOR v IN %d..%d OUTBOUND '%s/%s' %s RETURN v", index, index, documentCollection, key, edgeCollection)
 
-	queryString := fmt.Sprintf("FOR d IN %s FILTER d.create_time > '%d' && d.create_time < '%d' FOR v IN OUTBOUND d._id %s RETURN v", documentCollection, year, year+1, edgeCollection)
-	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed querying database")
-	}
-	defer cursor.Close()
+	var document arangoArtifact
+	err := readArangoQuery(ctx, db, queryString, nil, &document)
+	return document, err
+}
 
-	for {
-		var document arangoArtifact
+func sumArangoNeighbourNItems(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (int, error) {
+	queryString := "FOR d IN 0..@index OUTBOUND @start @@edges COLLECT item = d.item INTO g RETURN SUM(g[*].d.item)"
+	bindVars := map[string]interface{}{"index": index, "start": documentCollection + "/" + key, "@edges": edgeCollection}
 
-		_, err := cursor.ReadDocument(newCTX, &document)
+	var sum int
+	err := readArangoQuery(ctx, db, queryString, bindVars, &sum)
+	return sum, err
+}
 
-		if driver.IsNoMoreDocuments(err) {
-			break
-		}
+func sumArangoNeighbourNItemsInlined(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (int, error) {
+	queryString := fmt.Sprintf("FOR d IN 0..%d OUTBOUND '%s/%s' %s COLLECT item = d.item INTO g RETURN SUM(g[*].d.item)", index, documentCollection, key, edgeCollection)
 
-		if err != nil {
-			return 0, errors.Wrap(err, "failed reading document")
-		}
-	}
+	var sum int
+	err := readArangoQuery(ctx, db, queryString, nil, &sum)
+	return sum, err
+}
 
-	return int(cursor.Count()), nil
+func queryArangoSortedNeighbours(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string) (int, error) {
+	queryString := "FOR d IN OUTBOUND @start @@edges SORT d.name RETURN d"
+	return countArangoQuery(ctx, db, queryString, map[string]interface{}{"start": documentCollection + "/" + key, "@edges": edgeCollection})
 }
 
-func newChain(documentCollection string, size int) ([]arangoArtifact, []arangoEdge, error) {
+func queryArangoSortedNeighboursInlined(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string) (int, error) {
+	queryString := fmt.Sprintf("FOR d IN OUTBOUND '%s/%s' %s SORT d.name RETURN d", documentCollection, key, edgeCollection)
+	return countArangoQuery(ctx, db, queryString, nil)
+}
 
-	if size < 1 {
-		return nil, nil, nil
-	}
+func countArangoDocuments(ctx context.Context, db driver.Database, collection string) (int, error) {
 
-	key, err := uuid.NewUUID()
+	col, err := db.Collection(ctx, collection)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed creating uuid")
-	}
-	last := arangoArtifact{
-		Key:         key.String(),
-		Name:        "artifact-0",
-		Description: "description-0",
-		Item:        1,
-		CreateTime:  time.Now(),
+		return 0, errors.Wrap(err, "failed getting collection")
 	}
 
-	documents := []arangoArtifact{last}
-	var edges []arangoEdge
-
-	for i := 0; i < size-1; i++ {
-		key, err := uuid.NewUUID()
-		if err != nil {
-			return nil, nil, errors.Wrap(err, "failed creating uuid")
-		}
-		document := arangoArtifact{
-			Key:         key.String(),
-			Name:        fmt.Sprintf("artifact-%d", i+1),
-			Description: fmt.Sprintf("description-%d", i+1),
-			Item:        1,
-			CreateTime:  time.Now(),
-		}
-		edge := arangoEdge{
-			From: fmt.Sprintf("%s/%s", documentCollection, last.Key),
-			To:   fmt.Sprintf("%s/%s", documentCollection, key),
-			Body: fmt.Sprintf("body-%d", i),
-		}
-		documents = append(documents, document)
-		edges = append(edges, edge)
-		last = document
+	count, err := col.Count(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed counting documents")
 	}
 
-	return documents, edges, nil
+	return int(count), nil
 }
 
-// createArangoChain creates a chain of documents connected by edges. You can specify the chain size and number of chains.
-func createArangoChain(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, size, n int) ([]string, []string, int, int, error) {
+// readArangoKeys returns the keys of all documents of the collection ordered by their creation time.
+func readArangoKeys(ctx context.Context, db driver.Database, collection string) ([]string, error) {
 
-	// Document handling.
+	queryString := "FOR d IN @@collection SORT d.create_time, d._key RETURN d._key"
 
-	documentCol, err := db.Collection(ctx, documentCollection)
+	cursor, err := db.Query(ctx, queryString, map[string]interface{}{"@collection": collection})
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
+		return nil, errors.Wrap(err, "failed querying database")
 	}
+	defer cursor.Close()
 
-	var documents []arangoArtifact
-	var edges []arangoEdge
+	var keys []string
+	for {
+		var key string
 
-	for i := 0; i < n; i++ {
-		ds, es, err := newChain(documentCollection, size)
-		if err != nil {
-			return nil, nil, 0, 0, errors.Wrap(err, "failed allocating graph")
+		_, err := cursor.ReadDocument(ctx, &key)
+
+		if driver.IsNoMoreDocuments(err) {
+			break
 		}
 
-		documents = append(documents, ds...)
-		edges = append(edges, es...)
-	}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading key")
+		}
 
-	documentMetas, _, err := documentCol.CreateDocuments(ctx, documents)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating document")
+		keys = append(keys, key)
 	}
 
-	documentCount, err := documentCol.Count(ctx)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting documents")
-	}
+	return keys, nil
+}
 
-	// Edge handling.
+func removeArangoDocuments(ctx context.Context, db driver.Database, collection string, keys []string) error {
 
-	edgeCol, err := db.Collection(ctx, edgeCollection)
+	col, err := db.Collection(ctx, collection)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
+		return errors.Wrap(err, "failed getting collection")
 	}
 
-	edgeMetas, _, err := edgeCol.CreateDocuments(ctx, edges)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating edge")
+	if _, _, err := col.RemoveDocuments(ctx, keys); err != nil {
+		return errors.Wrap(err, "failed removing documents")
 	}
 
-	edgeCount, err := edgeCol.Count(ctx)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting edges")
-	}
+	return nil
+}
 
-	return documentMetas.Keys(), edgeMetas.Keys(), int(documentCount), int(edgeCount), nil
+// arangoBackend adapts the ArangoDB functions to the Backend interface. The inlined variant formats the values
+// into the AQL queries instead of binding them, as the benchmark did originally, so both can be compared.
+type arangoBackend struct {
+	db                 driver.Database
+	documentCollection string
+	edgeCollection     string
+	inlined            bool
 }
 
-func queryArangoNeighbourN(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (arangoArtifact, error) {
-	queryString := fmt.Sprintf("FOR v IN %d..%d OUTBOUND '%s/%s' %s RETURN v", index, index, documentCollection, key, edgeCollection)
-	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
-	if err != nil {
-		return arangoArtifact{}, errors.Wrap(err, "failed querying database")
-	}
-	defer cursor.Close()
+// NewArangoBackend connects to ArangoDB and makes sure the database and both collections exist.
+func NewArangoBackend(endpoint, dbName, documentCollection, edgeCollection string) (Backend, error) {
+	return newArangoBackend(endpoint, dbName, documentCollection, edgeCollection, false)
+}
 
-	var document arangoArtifact
+// NewInlinedArangoBackend is NewArangoBackend formatting the values into the queries.
+func NewInlinedArangoBackend(endpoint, dbName, documentCollection, edgeCollection string) (Backend, error) {
+	return newArangoBackend(endpoint, dbName, documentCollection, edgeCollection, true)
+}
 
-	_, err = cursor.ReadDocument(newCTX, &document)
+func newArangoBackend(endpoint, dbName, documentCollection, edgeCollection string, inlined bool) (Backend, error) {
 
-	if driver.IsNoMoreDocuments(err) {
-		return arangoArtifact{}, errors.New("no document found by query")
+	db, err := InitArango(endpoint, dbName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed initializing arangodb")
 	}
 
-	if err != nil {
-		return arangoArtifact{}, errors.Wrap(err, "failed reading document")
+	if err := CreateArangoDocumentCollection(db, documentCollection); err != nil {
+		return nil, errors.Wrap(err, "failed creating document collection")
+	}
+
+	if err := CreateArangoEdgeCollection(db, edgeCollection); err != nil {
+		return nil, errors.Wrap(err, "failed creating edge collection")
 	}
 
-	return document, nil
+	return &arangoBackend{db: db, documentCollection: documentCollection, edgeCollection: edgeCollection, inlined: inlined}, nil
 }
 
-func sumArangoNeighbourNItems(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string, index int) (int, error) {
-	queryString := fmt.Sprintf("FOR d IN 0..%d OUTBOUND '%s/%s' %s COLLECT item = d.item INTO g RETURN SUM(g[*].d.item)", index, documentCollection, key, edgeCollection)
-	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed querying database")
+func (b *arangoBackend) Name() string {
+	if b.inlined {
+		return "arangodb-inlined"
 	}
-	defer cursor.Close()
-
-	var length int
+	return "arangodb"
+}
 
-	_, err = cursor.ReadDocument(newCTX, &length)
+func (b *arangoBackend) Count(ctx context.Context) (int, int, error) {
 
-	if driver.IsNoMoreDocuments(err) {
-		return 0, errors.New("no document found by query")
+	documentCount, err := countArangoDocuments(ctx, b.db, b.documentCollection)
+	if err != nil {
+		return 0, 0, err
 	}
 
+	edgeCount, err := countArangoDocuments(ctx, b.db, b.edgeCollection)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed reading document")
+		return 0, 0, err
 	}
 
-	return length, nil
+	return documentCount, edgeCount, nil
 }
 
-// createArangoNeighbours creates one parents and n neighbours (direct connection).
-func createArangoNeighbours(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, n int) ([]string, []string, int, int, error) {
-
-	// Document handling.
-
-	key, err := uuid.NewUUID()
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating uuid")
-	}
-	tm := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+func (b *arangoBackend) Keys(ctx context.Context) ([]string, error) {
+	return readArangoKeys(ctx, b.db, b.documentCollection)
+}
 
-	parent := arangoArtifact{
-		Key:         key.String(),
-		Name:        fmt.Sprintf("artifact-0"),
-		Description: fmt.Sprintf("description-0"),
-		CreateTime:  tm,
-	}
+func (b *arangoBackend) Create(ctx context.Context, artifacts []Artifact) error {
+	return createArangoDocuments(ctx, b.db, b.documentCollection, artifacts)
+}
 
-	documents := []arangoArtifact{parent}
-	var edges []arangoEdge
+func (b *arangoBackend) BulkCreate(ctx context.Context, artifacts []Artifact) error {
+	return CreateBulkArangoDocuments(ctx, b.db, b.documentCollection, artifacts)
+}
 
-	for i := 0; i < n-1; i++ {
-		tm = tm.AddDate(0, 0, 1)
+func (b *arangoBackend) Read(ctx context.Context, key string) error {
+	return readOneArangoDocument(ctx, b.db, b.documentCollection, key)
+}
 
-		key, err := uuid.NewUUID()
-		if err != nil {
-			return nil, nil, 0, 0, errors.Wrap(err, "failed creating uuid")
-		}
+func (b *arangoBackend) BulkRead(ctx context.Context, keys []string) (int, error) {
+	return readBulkArangoDocuments(ctx, b.db, b.documentCollection, keys)
+}
 
-		document := arangoArtifact{
-			Key:         key.String(),
-			Name:        fmt.Sprintf("artifact-%d", i+1),
-			Description: fmt.Sprintf("description-%d", i+1),
-			CreateTime:  tm,
-		}
+func (b *arangoBackend) Update(ctx context.Context, artifact Artifact) error {
+	return updateOneArangoDocument(ctx, b.db, b.documentCollection, artifact)
+}
 
-		edge := arangoEdge{
-			From: fmt.Sprintf("%s/%s", documentCollection, parent.Key),
-			To:   fmt.Sprintf("%s/%s", documentCollection, document.Key),
-			Body: fmt.Sprintf("body-%d", i),
-		}
+func (b *arangoBackend) BulkUpdate(ctx context.Context, artifacts []Artifact) (int, error) {
+	return updateBulkArangoDocuments(ctx, b.db, b.documentCollection, artifacts)
+}
 
-		documents = append(documents, document)
-		edges = append(edges, edge)
+func (b *arangoBackend) Query(ctx context.Context, keys []string) (int, error) {
+	if b.inlined {
+		return queryArangoDocumentsInlined(ctx, b.db, b.documentCollection, keys)
 	}
+	return queryArangoDocuments(ctx, b.db, b.documentCollection, keys)
+}
 
-	documentCol, err := db.Collection(ctx, documentCollection)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
-	}
+func (b *arangoBackend) CreatePairs(ctx context.Context, dataset Dataset) error {
+	return CreateArangoGraph(ctx, b.db, b.documentCollection, b.edgeCollection, dataset)
+}
 
-	documentMetas, _, err := documentCol.CreateDocuments(ctx, documents)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating document")
-	}
+func (b *arangoBackend) QueryPairs(ctx context.Context) (int, error) {
+	return queryAllArangoPairs(ctx, b.db, b.documentCollection, b.edgeCollection)
+}
 
-	documentCount, err := documentCol.Count(ctx)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting documents")
+func (b *arangoBackend) QueryPairsInYear(ctx context.Context, year int) (int, error) {
+	if b.inlined {
+		return queryAllArangoPairsOneYearInlined(ctx, b.db, b.documentCollection, b.edgeCollection, year)
 	}
+	return queryAllArangoPairsOneYear(ctx, b.db, b.documentCollection, b.edgeCollection, year)
+}
 
-	// Edge handling.
+func (b *arangoBackend) CreateChain(ctx context.Context, dataset Dataset) error {
+	return CreateArangoGraph(ctx, b.db, b.documentCollection, b.edgeCollection, dataset)
+}
 
-	edgeCol, err := db.Collection(ctx, edgeCollection)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed getting collection")
-	}
+func (b *arangoBackend) QueryNeighbourN(ctx context.Context, key string, n int) (Artifact, error) {
 
-	edgeMetas, _, err := edgeCol.CreateDocuments(ctx, edges)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed creating edge")
+	query := queryArangoNeighbourN
+	if b.inlined {
+		query = queryArangoNeighbourNInlined
 	}
 
-	edgeCount, err := edgeCol.Count(ctx)
+	document, err := query(ctx, b.db, b.documentCollection, b.edgeCollection, key, n)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting edges")
+		return Artifact{}, err
 	}
 
-	return documentMetas.Keys(), edgeMetas.Keys(), int(documentCount), int(edgeCount), nil
+	return document.toArtifact(), nil
 }
 
-func queryArangoSortedNeighbours(ctx context.Context, db driver.Database, documentCollection, edgeCollection string, key string) (int, error) {
-	queryString := fmt.Sprintf("FOR d IN OUTBOUND '%s/%s' %s SORT d.name RETURN d", documentCollection, key, edgeCollection)
-	newCTX := driver.WithQueryCount(ctx)
-	cursor, err := db.Query(newCTX, queryString, nil)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed querying database")
+func (b *arangoBackend) SumNeighbourItems(ctx context.Context, key string, n int) (int, error) {
+	if b.inlined {
+		return sumArangoNeighbourNItemsInlined(ctx, b.db, b.documentCollection, b.edgeCollection, key, n)
 	}
-	defer cursor.Close()
+	return sumArangoNeighbourNItems(ctx, b.db, b.documentCollection, b.edgeCollection, key, n)
+}
 
-	for {
-		var document arangoArtifact
+func (b *arangoBackend) CreateNeighbours(ctx context.Context, dataset Dataset) error {
+	return CreateArangoGraph(ctx, b.db, b.documentCollection, b.edgeCollection, dataset)
+}
 
-		_, err := cursor.ReadDocument(newCTX, &document)
+func (b *arangoBackend) QuerySortedNeighbours(ctx context.Context, key string) (int, error) {
+	if b.inlined {
+		return queryArangoSortedNeighboursInlined(ctx, b.db, b.documentCollection, b.edgeCollection, key)
+	}
+	return queryArangoSortedNeighbours(ctx, b.db, b.documentCollection, b.edgeCollection, key)
+}
 
-		if driver.IsNoMoreDocuments(err) {
-			break
+func (b *arangoBackend) Cleanup(ctx context.Context, graph Graph) error {
+
+	if graph.EdgeKeys != nil {
+		if err := removeArangoDocuments(ctx, b.db, b.edgeCollection, graph.EdgeKeys); err != nil {
+			return errors.Wrap(err, "failed removing edges")
 		}
+	}
 
-		if err != nil {
-			return 0, errors.Wrap(err, "failed reading document")
+	if graph.ArtifactKeys != nil {
+		if err := removeArangoDocuments(ctx, b.db, b.documentCollection, graph.ArtifactKeys); err != nil {
+			return errors.Wrap(err, "failed removing documents")
 		}
 	}
 
-	return int(cursor.Count()), nil
+	return nil
+}
+
+func (b *arangoBackend) Close() error {
+	return nil
 }
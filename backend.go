@@ -0,0 +1,95 @@
+package db_bench
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotImplemented is returned by a backend that has no equivalent of the requested operation.
+var ErrNotImplemented = errors.New("not implemented")
+
+// Artifact is a backend independent view of a stored document (artifact, entity).
+type Artifact struct {
+	Key         string
+	Name        string
+	Description string
+	CreateTime  time.Time
+	Item        int
+
+	// Payload holds the extra fields generated by PayloadConfig: texts, numbers, byte slices, arrays and nested
+	// maps. It is nil if there is no payload.
+	Payload map[string]interface{}
+}
+
+// Graph holds the keys of artifacts and edges created by one operation.
+type Graph struct {
+	ArtifactKeys []string
+	EdgeKeys     []string
+}
+
+// Backend is implemented by every benchmarked database. All written data are generated by the caller in
+// advance, so the backend methods do the database work only.
+type Backend interface {
+
+	// Name returns a short, unique name of the backend.
+	Name() string
+
+	// Count returns the number of stored artifacts and edges.
+	Count(ctx context.Context) (int, int, error)
+
+	// Keys returns the keys of all stored artifacts ordered by their creation time, the oldest first.
+	Keys(ctx context.Context) ([]string, error)
+
+	// Create creates the artifacts one by one.
+	Create(ctx context.Context, artifacts []Artifact) error
+
+	// BulkCreate creates the artifacts in one operation.
+	BulkCreate(ctx context.Context, artifacts []Artifact) error
+
+	// Read reads one artifact by its key.
+	Read(ctx context.Context, key string) error
+
+	// BulkRead reads all artifacts by their keys in one operation and returns the number of artifacts read.
+	BulkRead(ctx context.Context, keys []string) (int, error)
+
+	// Update updates the artifact given by its key.
+	Update(ctx context.Context, artifact Artifact) error
+
+	// BulkUpdate updates all artifacts in one operation and returns the number of artifacts updated.
+	BulkUpdate(ctx context.Context, artifacts []Artifact) (int, error)
+
+	// Query reads all artifacts by their keys using a query and returns the number of artifacts read.
+	Query(ctx context.Context, keys []string) (int, error)
+
+	// CreatePairs creates the pairs generated by Generator.Pairs.
+	CreatePairs(ctx context.Context, dataset Dataset) error
+
+	// QueryPairs returns the number of neighbours in all pairs.
+	QueryPairs(ctx context.Context) (int, error)
+
+	// QueryPairsInYear returns the number of neighbours in pairs created in the given year.
+	QueryPairsInYear(ctx context.Context, year int) (int, error)
+
+	// CreateChain creates the chain generated by Generator.Chain.
+	CreateChain(ctx context.Context, dataset Dataset) error
+
+	// QueryNeighbourN returns the n-th neighbour of the artifact in a chain.
+	QueryNeighbourN(ctx context.Context, key string, n int) (Artifact, error)
+
+	// SumNeighbourItems sums the `item` fields of the artifact and its n following neighbours in a chain.
+	SumNeighbourItems(ctx context.Context, key string, n int) (int, error)
+
+	// CreateNeighbours creates the parent and its direct neighbours generated by Generator.Neighbours.
+	CreateNeighbours(ctx context.Context, dataset Dataset) error
+
+	// QuerySortedNeighbours returns the number of direct neighbours of the artifact sorted by name.
+	QuerySortedNeighbours(ctx context.Context, key string) (int, error)
+
+	// Cleanup removes the given artifacts and edges.
+	Cleanup(ctx context.Context, graph Graph) error
+
+	// Close releases all resources held by the backend.
+	Close() error
+}
@@ -0,0 +1,502 @@
+package db_bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The buckets of the bbolt graph store. Artifacts and edges are JSON documents by their keys, the adjacency
+// list holds keys of `<artifact key>/<edge key>` pointing to the target of the outgoing edge, so the
+// neighbours of an artifact are read by one cursor scan over the prefix. All scenarios follow the edges
+// forward, there is no list of the incoming ones.
+var (
+	boltArtifactBucket = []byte("artifacts")
+	boltEdgeBucket     = []byte("edges")
+	boltOutBucket      = []byte("out")
+)
+
+// boltOpenTimeout limits waiting for the file lock held by another process.
+const boltOpenTimeout = 10 * time.Second
+
+func InitBolt(path string) (*bolt.DB, error) {
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening bbolt database")
+	}
+
+	return db, nil
+}
+
+// CreateBoltBuckets creates the buckets of the artifacts, edges and the adjacency list.
+func CreateBoltBuckets(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltArtifactBucket, boltEdgeBucket, boltOutBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return errors.Wrapf(err, "failed creating bucket %s", name)
+			}
+		}
+		return nil
+	})
+}
+
+// boltAdjacencyKey is the key of the edge in the adjacency list of the artifact.
+func boltAdjacencyKey(artifactKey, edgeKey string) []byte {
+	return []byte(artifactKey + "/" + edgeKey)
+}
+
+func boltAdjacencyPrefix(artifactKey string) []byte {
+	return []byte(artifactKey + "/")
+}
+
+func getBoltArtifact(tx *bolt.Tx, key []byte) (Artifact, error) {
+
+	var artifact Artifact
+
+	value := tx.Bucket(boltArtifactBucket).Get(key)
+	if value == nil {
+		return artifact, errors.Errorf("artifact %s not found", key)
+	}
+
+	if err := json.Unmarshal(value, &artifact); err != nil {
+		return artifact, errors.Wrap(err, "failed decoding artifact")
+	}
+
+	return artifact, nil
+}
+
+func putBoltArtifact(bucket *bolt.Bucket, artifact Artifact) error {
+
+	value, err := json.Marshal(artifact)
+	if err != nil {
+		return errors.Wrap(err, "failed encoding artifact")
+	}
+
+	return bucket.Put([]byte(artifact.Key), value)
+}
+
+// insertBoltGraph stores the artifacts, the edges and the adjacency list within the transaction. Existing
+// keys are rejected like a primary key would.
+func insertBoltGraph(tx *bolt.Tx, dataset Dataset) error {
+
+	artifacts := tx.Bucket(boltArtifactBucket)
+	for _, artifact := range dataset.Artifacts {
+		if artifacts.Get([]byte(artifact.Key)) != nil {
+			return errors.Errorf("artifact %s already exists", artifact.Key)
+		}
+		if err := putBoltArtifact(artifacts, artifact); err != nil {
+			return errors.Wrap(err, "failed inserting artifact")
+		}
+	}
+
+	edges := tx.Bucket(boltEdgeBucket)
+	out := tx.Bucket(boltOutBucket)
+
+	for _, edge := range dataset.Edges {
+		if edges.Get([]byte(edge.Key)) != nil {
+			return errors.Errorf("edge %s already exists", edge.Key)
+		}
+
+		value, err := json.Marshal(edge)
+		if err != nil {
+			return errors.Wrap(err, "failed encoding edge")
+		}
+
+		if err := edges.Put([]byte(edge.Key), value); err != nil {
+			return errors.Wrap(err, "failed inserting edge")
+		}
+		if err := out.Put(boltAdjacencyKey(edge.From, edge.Key), []byte(edge.To)); err != nil {
+			return errors.Wrap(err, "failed inserting outgoing edge")
+		}
+	}
+
+	return nil
+}
+
+func createBoltArtifacts(db *bolt.DB, artifacts []Artifact) error {
+
+	for _, artifact := range artifacts {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return insertBoltGraph(tx, Dataset{Artifacts: []Artifact{artifact}})
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed creating artifact")
+		}
+	}
+
+	return nil
+}
+
+// createBoltGraph creates the artifacts and the edges connecting them in one transaction. It is used for bulk
+// creation, pairs, chains and neighbours.
+func createBoltGraph(db *bolt.DB, dataset Dataset) error {
+
+	err := db.Update(func(tx *bolt.Tx) error { return insertBoltGraph(tx, dataset) })
+	if err != nil {
+		return errors.Wrap(err, "failed creating graph")
+	}
+
+	return nil
+}
+
+func readOneBoltArtifact(db *bolt.DB, key string) error {
+	return db.View(func(tx *bolt.Tx) error {
+		_, err := getBoltArtifact(tx, []byte(key))
+		return err
+	})
+}
+
+// readBulkBoltArtifacts reads and decodes the artifacts in one transaction and counts the found ones.
+func readBulkBoltArtifacts(db *bolt.DB, keys []string) (int, error) {
+
+	var count int
+
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltArtifactBucket)
+		for _, key := range keys {
+			value := bucket.Get([]byte(key))
+			if value == nil {
+				continue
+			}
+			var artifact Artifact
+			if err := json.Unmarshal(value, &artifact); err != nil {
+				return errors.Wrap(err, "failed decoding artifact")
+			}
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// queryBoltArtifacts has no query language to go through, it reads every artifact in its own transaction.
+func queryBoltArtifacts(db *bolt.DB, keys []string) (int, error) {
+
+	var count int
+
+	for _, key := range keys {
+		err := db.View(func(tx *bolt.Tx) error {
+			if tx.Bucket(boltArtifactBucket).Get([]byte(key)) != nil {
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed reading artifact")
+		}
+	}
+
+	return count, nil
+}
+
+// updateBoltArtifacts changes the name and description of the existing artifacts in one transaction and
+// returns the number of the updated ones.
+func updateBoltArtifacts(db *bolt.DB, artifacts []Artifact) (int, error) {
+
+	var count int
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltArtifactBucket)
+		for _, artifact := range artifacts {
+			if bucket.Get([]byte(artifact.Key)) == nil {
+				continue
+			}
+			stored, err := getBoltArtifact(tx, []byte(artifact.Key))
+			if err != nil {
+				return err
+			}
+			stored.Name = artifact.Name
+			stored.Description = artifact.Description
+			if err := putBoltArtifact(bucket, stored); err != nil {
+				return errors.Wrap(err, "failed updating artifact")
+			}
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// queryAllBoltPairs scans all edges and counts the ones whose target artifact matches.
+func queryAllBoltPairs(db *bolt.DB, match func(to Artifact) bool) (int, error) {
+
+	var count int
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltEdgeBucket).ForEach(func(_, value []byte) error {
+			var edge Edge
+			if err := json.Unmarshal(value, &edge); err != nil {
+				return errors.Wrap(err, "failed decoding edge")
+			}
+			to, err := getBoltArtifact(tx, []byte(edge.To))
+			if err != nil {
+				return err
+			}
+			if match(to) {
+				count++
+			}
+			return nil
+		})
+	})
+
+	return count, err
+}
+
+// walkBoltChain visits the artifact and its n following neighbours, always taking the first outgoing edge.
+func walkBoltChain(db *bolt.DB, key string, n int, visit func(Artifact)) error {
+	return db.View(func(tx *bolt.Tx) error {
+
+		artifact, err := getBoltArtifact(tx, []byte(key))
+		if err != nil {
+			return err
+		}
+		visit(artifact)
+
+		cursor := tx.Bucket(boltOutBucket).Cursor()
+		for i := 0; i < n; i++ {
+			prefix := boltAdjacencyPrefix(artifact.Key)
+			k, to := cursor.Seek(prefix)
+			if k == nil || !bytes.HasPrefix(k, prefix) {
+				return errors.Errorf("chain ends after %d neighbours", i)
+			}
+			if artifact, err = getBoltArtifact(tx, to); err != nil {
+				return err
+			}
+			visit(artifact)
+		}
+
+		return nil
+	})
+}
+
+func queryBoltSortedNeighbours(db *bolt.DB, key string) (int, error) {
+
+	var names []string
+
+	err := db.View(func(tx *bolt.Tx) error {
+		prefix := boltAdjacencyPrefix(key)
+		cursor := tx.Bucket(boltOutBucket).Cursor()
+		for k, to := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, to = cursor.Next() {
+			artifact, err := getBoltArtifact(tx, to)
+			if err != nil {
+				return err
+			}
+			names = append(names, artifact.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed querying neighbours")
+	}
+
+	sort.Strings(names)
+
+	return len(names), nil
+}
+
+// readBoltKeys returns the keys of all artifacts ordered by their creation time. The artifacts are indexed by
+// their keys only, all of them are decoded.
+func readBoltKeys(db *bolt.DB) ([]string, error) {
+
+	var artifacts []Artifact
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltArtifactBucket).ForEach(func(key, value []byte) error {
+			var artifact Artifact
+			if err := json.Unmarshal(value, &artifact); err != nil {
+				return errors.Wrap(err, "failed decoding artifact")
+			}
+			artifacts = append(artifacts, Artifact{Key: artifact.Key, CreateTime: artifact.CreateTime})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortArtifacts(artifacts)
+
+	return artifactKeys(artifacts), nil
+}
+
+func countBoltBuckets(db *bolt.DB) (int, int, error) {
+
+	var artifactCounter int
+	var edgeCounter int
+
+	err := db.View(func(tx *bolt.Tx) error {
+		artifactCounter = tx.Bucket(boltArtifactBucket).Stats().KeyN
+		edgeCounter = tx.Bucket(boltEdgeBucket).Stats().KeyN
+		return nil
+	})
+
+	return artifactCounter, edgeCounter, err
+}
+
+// removeBoltGraph removes the edges together with the adjacency list and the artifacts in one transaction.
+func removeBoltGraph(db *bolt.DB, graph Graph) error {
+
+	err := db.Update(func(tx *bolt.Tx) error {
+
+		edges := tx.Bucket(boltEdgeBucket)
+		out := tx.Bucket(boltOutBucket)
+
+		for _, key := range graph.EdgeKeys {
+			value := edges.Get([]byte(key))
+			if value == nil {
+				continue
+			}
+			var edge Edge
+			if err := json.Unmarshal(value, &edge); err != nil {
+				return errors.Wrap(err, "failed decoding edge")
+			}
+			if err := out.Delete(boltAdjacencyKey(edge.From, edge.Key)); err != nil {
+				return err
+			}
+			if err := edges.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		artifacts := tx.Bucket(boltArtifactBucket)
+		for _, key := range graph.ArtifactKeys {
+			if err := artifacts.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed removing graph")
+	}
+
+	return nil
+}
+
+// boltBackend adapts the bbolt functions to the Backend interface. The database file is locked by one
+// process, the workers of a run have to share the backend, which is safe for concurrent use.
+type boltBackend struct {
+	db *bolt.DB
+}
+
+// NewBoltBackend opens (or creates) the bbolt database file and makes sure the buckets exist.
+func NewBoltBackend(path string) (Backend, error) {
+
+	db, err := InitBolt(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed initializing bbolt")
+	}
+
+	if err := CreateBoltBuckets(db); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed creating buckets")
+	}
+
+	return &boltBackend{db: db}, nil
+}
+
+func (b *boltBackend) Name() string {
+	return "bbolt"
+}
+
+func (b *boltBackend) Count(_ context.Context) (int, int, error) {
+	return countBoltBuckets(b.db)
+}
+
+func (b *boltBackend) Keys(_ context.Context) ([]string, error) {
+	return readBoltKeys(b.db)
+}
+
+func (b *boltBackend) Create(_ context.Context, artifacts []Artifact) error {
+	return createBoltArtifacts(b.db, artifacts)
+}
+
+func (b *boltBackend) BulkCreate(_ context.Context, artifacts []Artifact) error {
+	return createBoltGraph(b.db, Dataset{Artifacts: artifacts})
+}
+
+func (b *boltBackend) Read(_ context.Context, key string) error {
+	return readOneBoltArtifact(b.db, key)
+}
+
+func (b *boltBackend) BulkRead(_ context.Context, keys []string) (int, error) {
+	return readBulkBoltArtifacts(b.db, keys)
+}
+
+func (b *boltBackend) Update(_ context.Context, artifact Artifact) error {
+	_, err := updateBoltArtifacts(b.db, []Artifact{artifact})
+	return err
+}
+
+func (b *boltBackend) BulkUpdate(_ context.Context, artifacts []Artifact) (int, error) {
+	return updateBoltArtifacts(b.db, artifacts)
+}
+
+func (b *boltBackend) Query(_ context.Context, keys []string) (int, error) {
+	return queryBoltArtifacts(b.db, keys)
+}
+
+func (b *boltBackend) CreatePairs(_ context.Context, dataset Dataset) error {
+	return createBoltGraph(b.db, dataset)
+}
+
+func (b *boltBackend) QueryPairs(_ context.Context) (int, error) {
+	return queryAllBoltPairs(b.db, func(Artifact) bool { return true })
+}
+
+func (b *boltBackend) QueryPairsInYear(_ context.Context, year int) (int, error) {
+	return queryAllBoltPairs(b.db, func(to Artifact) bool { return to.CreateTime.UTC().Year() == year })
+}
+
+func (b *boltBackend) CreateChain(_ context.Context, dataset Dataset) error {
+	return createBoltGraph(b.db, dataset)
+}
+
+func (b *boltBackend) QueryNeighbourN(_ context.Context, key string, n int) (Artifact, error) {
+
+	var last Artifact
+
+	err := walkBoltChain(b.db, key, n, func(artifact Artifact) { last = artifact })
+	if err != nil {
+		return Artifact{}, errors.Wrap(err, "failed searching in chain")
+	}
+
+	return last, nil
+}
+
+func (b *boltBackend) SumNeighbourItems(_ context.Context, key string, n int) (int, error) {
+
+	var sum int
+
+	err := walkBoltChain(b.db, key, n, func(artifact Artifact) { sum += artifact.Item })
+	if err != nil {
+		return 0, errors.Wrap(err, "failed searching in chain")
+	}
+
+	return sum, nil
+}
+
+func (b *boltBackend) CreateNeighbours(_ context.Context, dataset Dataset) error {
+	return createBoltGraph(b.db, dataset)
+}
+
+func (b *boltBackend) QuerySortedNeighbours(_ context.Context, key string) (int, error) {
+	return queryBoltSortedNeighbours(b.db, key)
+}
+
+func (b *boltBackend) Cleanup(_ context.Context, graph Graph) error {
+	return removeBoltGraph(b.db, graph)
+}
+
+func (b *boltBackend) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,27 @@
+package db_bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltSuite(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewBoltBackend(config.Bolt.Path)
+	require.NoError(t, err)
+
+	runScenarios(t, config, backend, NewMemoryBackend())
+}
+
+func TestBoltQueries(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewBoltBackend(config.Bolt.Path)
+	require.NoError(t, err)
+
+	runQueries(t, backend)
+}
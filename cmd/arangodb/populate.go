@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"os"
+	"time"
 )
 
 func main() {
@@ -19,64 +20,67 @@ func main() {
 
 func run() error {
 
-	var endpoint string
-	var n int
-	var chunk int
-
-	flag.StringVar(&endpoint, "endpoint", dbBench.ArangoEndpoint, "ArangoDB endpoint")
-	flag.IntVar(&n, "n", 1000000, "the number of entries to generate inside DB")
-	flag.IntVar(&chunk, "chunk", 10000, "maximum inserts of one bulk operation")
-	flag.Parse()
-
-	db, err := dbBench.InitArango(endpoint, dbBench.ArangoDB)
+	configPath := dbBench.ConfigPath(os.Args[1:])
+	config, err := dbBench.LoadConfig(configPath)
 	if err != nil {
-		return errors.Wrap(err, "failed initializing postgres connection")
+		return err
 	}
 
-	err = dbBench.CreateArangoDocumentCollection(db, dbBench.ArangoDocumentTestCollection)
-	if err != nil {
-		return errors.Wrap(err, "failed creating document collection")
-	}
+	flag.String("config", configPath, "YAML config file, $"+dbBench.ConfigEnv+" by default")
+	flag.StringVar(&config.Arango.Endpoint, "endpoint", config.Arango.Endpoint, "ArangoDB endpoint")
+	flag.IntVar(&config.Populate.N, "n", config.Populate.N, "the number of entries to generate inside DB")
+	flag.IntVar(&config.Populate.Chunk, "chunk", config.Populate.Chunk, "maximum inserts of one bulk operation")
+	flag.StringVar(&config.Populate.Topology, "topology", config.Populate.Topology, "topology of the edges: none, random, preferential, forest or chains")
+	flag.IntVar(&config.Populate.Edges, "edges", config.Populate.Edges, "the number of edges to generate inside DB")
+	flag.IntVar(&config.Populate.Workers, "workers", config.Populate.Workers, "the number of concurrent writers")
+	flag.StringVar(&config.Populate.Checkpoint, "checkpoint", config.Populate.Checkpoint, "directory of the checkpoints resuming an interrupted run")
+	flag.IntVar(&config.Populate.Seed, "seed", config.Populate.Seed, "seed of the generated data, the same seed populates every database with the same data")
+	flag.IntVar(&config.Payload.Fields, "payload-fields", config.Payload.Fields, "number of extra text fields of every entry")
+	flag.IntVar(&config.Payload.Depth, "payload-depth", config.Payload.Depth, "depth of a nested object of every entry")
+	flag.IntVar(&config.Payload.Array, "payload-array", config.Payload.Array, "length of an array of numbers of every entry")
+	flag.IntVar(&config.Payload.Text, "payload-text", config.Payload.Text, "size in bytes of a text field of every entry")
+	flag.IntVar(&config.Payload.Binary, "payload-binary", config.Payload.Binary, "size in bytes of a binary field of every entry")
+	flag.StringVar(&config.Payload.Distribution, "payload-distribution", config.Payload.Distribution, "distribution of the text and binary sizes: fixed, uniform or exponential")
+	flag.Parse()
 
-	err = dbBench.CreateArangoEdgeCollection(db, dbBench.ArangoEdgeTestCollection)
-	if err != nil {
-		return errors.Wrap(err, "failed creating edge collection")
+	if err := config.Validate(); err != nil {
+		return err
 	}
 
-	ctx := context.Background()
+	n := config.Populate.N
+	documentCollection := config.Arango.DocumentCollection
+	edgeCollection := config.Arango.EdgeCollection
 
-	col, err := db.Collection(ctx, dbBench.ArangoDocumentTestCollection)
+	// The backend creates the collections, counts the documents and removes the unfinished bulks.
+	backend, err := dbBench.NewArangoBackend(config.Arango.Endpoint, config.Arango.Database, documentCollection, edgeCollection)
 	if err != nil {
-		return errors.Wrap(err, "failed accessing document collection")
+		return errors.Wrap(err, "failed initializing arangodb backend")
 	}
+	defer backend.Close()
 
-	docCount, err := col.Count(ctx)
+	db, err := dbBench.InitArango(config.Arango.Endpoint, config.Arango.Database)
 	if err != nil {
-		return errors.Wrap(err, "failed counting documents")
-	}
-
-	docs := int(docCount)
-
-	if docs >= n {
-		return errors.New("db is already populated")
+		return errors.Wrap(err, "failed initializing arangodb connection")
 	}
 
-	log.Info().Int("docs", docs).Msg("pre-feeding status")
-
-	total := n - docs
-
-	actual := 0
-	for actual < total {
-		bulkCount := chunk
-		if actual+bulkCount > total {
-			bulkCount = total - actual
-		}
-		if _, _, err := dbBench.CreateBulkArangoDocuments(ctx, db, dbBench.ArangoDocumentTestCollection, bulkCount); err != nil {
-			return errors.Wrap(err, "failed creating artifacts")
-		}
-		actual += bulkCount
-		log.Info().Int("count", actual).Float64("perc", (float64(actual+docs)/float64(n))*100.0).Msg("status")
+	populator := dbBench.Populator{
+		Backend: backend,
+		Write: func(ctx context.Context, dataset dbBench.Dataset) error {
+			return dbBench.CreateArangoGraph(ctx, db, documentCollection, edgeCollection, dataset)
+		},
+		Config:  config.Populate,
+		Payload: config.Payload,
+		Progress: func(p dbBench.PopulateProgress) {
+			log.Info().
+				Int("stored", p.Stored).
+				Int("count", p.Rows).
+				Int("edges", p.Edges).
+				Float64("perc", (float64(p.Rows+p.Stored)/float64(n))*100.0).
+				Float64("rows_per_sec", p.RowsPerSec).
+				Str("eta", p.ETA.Round(time.Second).String()).
+				Msg("status")
+		},
 	}
 
-	return nil
+	return populator.Run(context.Background())
 }
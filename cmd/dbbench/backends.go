@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/pkg/errors"
+)
+
+var backendNames = []string{"arangodb", "postgres", "neo4j", "sqlite", "bbolt", "memory"}
+
+// variantNames are the backends formatting the values into the queries instead of binding them and the
+// PostgreSQL backends inserting in bulk by other strategies than COPY or talking by the pgx driver, they are run
+// only if asked for.
+var variantNames = []string{"arangodb-inlined", "postgres-inlined", "postgres-values", "postgres-unnest", "pgx"}
+
+// loadConfig loads the configuration given by the `-config` argument and registers the flag, so the flags of
+// the command default to the configured values.
+func loadConfig(fs *flag.FlagSet, args []string) (dbBench.Config, error) {
+	path := dbBench.ConfigPath(args)
+	fs.String("config", path, "YAML config file, $"+dbBench.ConfigEnv+" by default")
+	return dbBench.LoadConfig(path)
+}
+
+// registerBackendFlags binds the connection settings of all backends to the flags.
+func registerBackendFlags(fs *flag.FlagSet, config *dbBench.Config) {
+	fs.StringVar(&config.Arango.Endpoint, "arango-endpoint", config.Arango.Endpoint, "ArangoDB endpoint")
+	fs.StringVar(&config.Arango.Database, "arango-db", config.Arango.Database, "ArangoDB database name")
+	fs.StringVar(&config.Postgres.ConnStr, "postgres", config.Postgres.ConnStr, "connection string to PostgreSQL")
+	fs.StringVar(&config.Neo4j.Endpoint, "neo4j-endpoint", config.Neo4j.Endpoint, "Neo4j endpoint")
+	fs.StringVar(&config.Neo4j.Username, "neo4j-username", config.Neo4j.Username, "Neo4j username")
+	fs.StringVar(&config.Neo4j.Password, "neo4j-password", config.Neo4j.Password, "Neo4j password")
+	fs.StringVar(&config.SQLite.Path, "sqlite", config.SQLite.Path, "SQLite database file")
+	fs.StringVar(&config.Bolt.Path, "bolt", config.Bolt.Path, "bbolt database file")
+}
+
+// sharedBackend tells whether the backend can be opened only once per process, its workers share it then.
+// Every in-memory backend is a separate store and the bbolt file is locked by its first opening.
+func sharedBackend(name string) bool {
+	return name == "memory" || name == "bbolt"
+}
+
+// openBackend connects to the backend by its name.
+func openBackend(config dbBench.Config, name string) (dbBench.Backend, error) {
+	switch name {
+	case "arangodb":
+		return dbBench.NewArangoBackend(config.Arango.Endpoint, config.Arango.Database, config.Arango.DocumentCollection, config.Arango.EdgeCollection)
+	case "arangodb-inlined":
+		return dbBench.NewInlinedArangoBackend(config.Arango.Endpoint, config.Arango.Database, config.Arango.DocumentCollection, config.Arango.EdgeCollection)
+	case "postgres":
+		return dbBench.NewPostgresBackend(config.Postgres.ConnStr)
+	case "postgres-inlined":
+		return dbBench.NewInlinedPostgresBackend(config.Postgres.ConnStr)
+	case "postgres-values":
+		return dbBench.NewPostgresBulkBackend(config.Postgres.ConnStr, dbBench.PostgresBulkValues)
+	case "postgres-unnest":
+		return dbBench.NewPostgresBulkBackend(config.Postgres.ConnStr, dbBench.PostgresBulkUnnest)
+	case "pgx":
+		return dbBench.NewPgxBackend(config.Postgres.ConnStr)
+	case "neo4j":
+		return dbBench.NewNeo4jBackend(config.Neo4j.Endpoint, config.Neo4j.Username, config.Neo4j.Password)
+	case "sqlite":
+		return dbBench.NewSQLiteBackend(config.SQLite.Path)
+	case "bbolt":
+		return dbBench.NewBoltBackend(config.Bolt.Path)
+	case "memory":
+		return dbBench.NewMemoryBackend(), nil
+	default:
+		return nil, errors.Errorf("unknown backend %s", name)
+	}
+}
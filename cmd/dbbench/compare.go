@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+func compareCommand(args []string) error {
+
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dbbench compare [flags] <base.json> <new.json>")
+		fs.PrintDefaults()
+	}
+	threshold := fs.Float64("threshold", 10, "minimal change of the median in percent reported as a regression or an improvement")
+	alpha := fs.Float64("alpha", 0.05, "significance level of the Mann-Whitney U test")
+	all := fs.Bool("all", false, "print unchanged and incomparable scenarios too")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected two result files")
+	}
+
+	base, err := readReport(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	current, err := readReport(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	comparisons := dbBench.Compare(base.Results, current.Results, *threshold/100, *alpha)
+
+	if err := printComparisons(os.Stdout, comparisons, *all); err != nil {
+		return err
+	}
+
+	var regressions, incomparable int
+	for _, comparison := range comparisons {
+		if comparison.Verdict == dbBench.VerdictRegression || comparison.Verdict == dbBench.VerdictBroken {
+			regressions++
+		}
+		if tooFewIterations(comparison) {
+			incomparable++
+		}
+	}
+
+	// A run of too few iterations would pass any slowdown, it is reported but does not fail the comparison.
+	if incomparable > 0 {
+		log.Warn().Int("scenarios", incomparable).Float64("alpha", *alpha).Msg("not enough samples to tell a change, run more -iterations")
+	}
+
+	if regressions > 0 {
+		return errors.Errorf("%d scenarios regressed", regressions)
+	}
+
+	return nil
+}
+
+// printComparisons prints the changed scenarios (or all of them) with their medians, delta and p-value.
+func printComparisons(out io.Writer, comparisons []dbBench.Comparison, all bool) error {
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "BACKEND\tNUM\tSCENARIO\tBASE\tNEW\tDELTA\tP\tVERDICT\t")
+	for _, c := range comparisons {
+		changed := c.Verdict != dbBench.VerdictSame && c.Verdict != dbBench.VerdictIncomparable || tooFewIterations(c)
+		if !changed && !all {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t", c.Backend, c.Num, c.Scenario, formatMedian(c.Base), formatMedian(c.New))
+		if tooFewIterations(c) {
+			fmt.Fprintf(w, "-\t-\tnot enough samples\t\n")
+			continue
+		}
+		if c.Verdict == dbBench.VerdictBroken || c.Verdict == dbBench.VerdictIncomparable {
+			fmt.Fprintf(w, "-\t-\t%s\t\n", c.Verdict)
+			continue
+		}
+		fmt.Fprintf(w, "%+.1f %%\t%.3f\t%s\t\n", c.Delta*100, c.PValue, c.Verdict)
+	}
+
+	return w.Flush()
+}
+
+// tooFewIterations tells whether the scenario passed in both runs and is incomparable anyway.
+func tooFewIterations(c dbBench.Comparison) bool {
+	return c.Verdict == dbBench.VerdictIncomparable && c.Base.Status == dbBench.StatusOK && c.New.Status == dbBench.StatusOK
+}
+
+func formatMedian(result dbBench.Result) string {
+	if result.Status != dbBench.StatusOK {
+		return string(result.Status)
+	}
+	return formatDuration(result.Stats.Median)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	dbBench "github.com/geomodular/db-bench"
+)
+
+func listCommand(args []string) error {
+
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+
+	config, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	fs.Parse(args)
+	config.ConfigureScenarios()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "BACKENDS")
+	for _, name := range backendNames {
+		fmt.Fprintf(w, "%s\n", name)
+	}
+	for _, name := range variantNames {
+		fmt.Fprintf(w, "%s\t(variant)\n", name)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "NUM\tSCENARIO\tDEPENDS\tPARAMS\tTITLE")
+	for _, scenario := range dbBench.Scenarios {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", scenario.Num, scenario.Name, scenario.Depends, scenario.Params, scenario.Title)
+	}
+
+	return w.Flush()
+}
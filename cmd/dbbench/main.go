@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const usage = `Usage: dbbench <command> [flags]
+
+Commands:
+  run      run scenarios against backends
+  list     list available backends and scenarios
+  report   print results recorded by run
+  compare  compare two recorded runs and fail on regressions
+
+Use "dbbench <command> -h" for the command flags.
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Error().Err(err).Msg("")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func run(args []string) error {
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("missing command")
+	}
+
+	switch args[0] {
+	case "run":
+		return runCommand(args[1:])
+	case "list":
+		return listCommand(args[1:])
+	case "report":
+		return reportCommand(args[1:])
+	case "compare":
+		return compareCommand(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return errors.Errorf("unknown command %s", args[0])
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"os"
+
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/pkg/errors"
+)
+
+// The generated tables are placed between these markers in the README.
+const (
+	readmeBegin = "<!-- dbbench:results:begin -->"
+	readmeEnd   = "<!-- dbbench:results:end -->"
+)
+
+// updateReadme replaces the text between the result markers of the README with the tables of the results.
+func updateReadme(path string, results []dbBench.Result) error {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed reading readme")
+	}
+
+	begin := bytes.Index(content, []byte(readmeBegin))
+	end := bytes.Index(content, []byte(readmeEnd))
+	if begin < 0 || end < begin {
+		return errors.Errorf("missing %s and %s markers in %s", readmeBegin, readmeEnd, path)
+	}
+
+	var tables bytes.Buffer
+	if err := dbBench.WriteMarkdown(&tables, results); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	b.Write(content[:begin+len(readmeBegin)])
+	b.WriteString("\n\n")
+	b.Write(tables.Bytes())
+	b.WriteString("\n")
+	b.Write(content[end:])
+
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "failed writing readme")
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/pkg/errors"
+)
+
+func reportCommand(args []string) error {
+
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dbbench report [table|csv|markdown] [flags] <result.json>...")
+		fs.PrintDefaults()
+	}
+
+	format := "table"
+	if len(args) > 0 && (args[0] == "table" || args[0] == "csv" || args[0] == "markdown") {
+		format = args[0]
+		args = args[1:]
+	}
+
+	readme := fs.String("readme", "", "markdown only: replace the results section of the given README instead of printing")
+	fs.Parse(args)
+
+	files := fs.Args()
+
+	if len(files) == 0 {
+		fs.Usage()
+		return errors.New("missing result file")
+	}
+
+	var report dbBench.Report
+
+	for _, path := range files {
+		r, err := readReport(path)
+		if err != nil {
+			return err
+		}
+		report.Results = append(report.Results, r.Results...)
+	}
+
+	switch {
+	case format == "csv":
+		return dbBench.WriteCSV(os.Stdout, report)
+	case format == "markdown" && *readme != "":
+		return updateReadme(*readme, report.Results)
+	case format == "markdown":
+		return dbBench.WriteMarkdown(os.Stdout, report.Results)
+	}
+
+	if err := printResults(os.Stdout, report.Results); err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		if result.Rate > 0 {
+			fmt.Println()
+			return printSweep(os.Stdout, report.Results)
+		}
+	}
+
+	return nil
+}
+
+func readReport(path string) (dbBench.Report, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return dbBench.Report{}, errors.Wrap(err, "failed opening result file")
+	}
+	defer f.Close()
+
+	report, err := dbBench.ReadReport(f)
+	if err != nil {
+		return dbBench.Report{}, errors.Wrapf(err, "failed reading %s", path)
+	}
+
+	return report, nil
+}
+
+func writeReport(path string, report dbBench.Report) error {
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed creating result file")
+	}
+
+	if err := dbBench.WriteReport(f, report); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeCSV(path string, report dbBench.Report) error {
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed creating csv file")
+	}
+
+	if err := dbBench.WriteCSV(f, report); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// printResults prints the results with their statistics as a table. The prepare and verify phases are shown by
+// their medians. Throughput and operation latencies are added if any of the results comes from a concurrent run.
+func printResults(out io.Writer, results []dbBench.Result) error {
+
+	concurrent := false
+	for _, result := range results {
+		if result.Workers > 0 {
+			concurrent = true
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprint(w, "BACKEND\tNUM\tSCENARIO\tSTATUS\tN\tMIN\tMEAN\tMEDIAN\tP90\tP99\tMAX\tSTDDEV\tPREPARE\tVERIFY\t")
+	if concurrent {
+		fmt.Fprint(w, "WORKERS\tOPS/S\tOP-P50\tOP-P90\tOP-P99\t")
+	}
+	fmt.Fprintln(w)
+
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t", result.Backend, result.Num, result.Scenario, result.Status)
+		if result.Status != dbBench.StatusOK {
+			fmt.Fprintln(w, "-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t")
+			continue
+		}
+		stats := result.Stats
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t", stats.Count, formatDuration(stats.Min), formatDuration(stats.Mean),
+			formatDuration(stats.Median), formatDuration(stats.P90), formatDuration(stats.P99), formatDuration(stats.Max),
+			formatDuration(stats.StdDev), formatDuration(result.Prepare.Median), formatDuration(result.Verify.Median))
+		if concurrent && result.Workers > 0 {
+			latency := result.Latency
+			fmt.Fprintf(w, "%d\t%.0f\t%s\t%s\t%s\t", result.Workers, result.Throughput, formatDuration(latency.Median),
+				formatDuration(latency.P90), formatDuration(latency.P99))
+		} else if concurrent {
+			fmt.Fprint(w, "-\t-\t-\t-\t-\t")
+		}
+		fmt.Fprintln(w)
+	}
+
+	return w.Flush()
+}
+
+// printSweep prints the open-loop results of the rate sweep, the target rate next to the achieved throughput and
+// the latencies measured from the intended start and from the actual start (service time) of the operations.
+func printSweep(out io.Writer, results []dbBench.Result) error {
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "BACKEND\tSCENARIO\tRATE\tOPS/S\tP50\tP90\tP99\tMAX\tSERVICE-P50\tSERVICE-P99\tSATURATED\t")
+	for _, result := range results {
+		if result.Rate == 0 || result.Status != dbBench.StatusOK {
+			continue
+		}
+		latency := result.Latency
+		saturated := ""
+		if result.Saturated() {
+			saturated = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.0f\t%.0f\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", result.Backend, result.Scenario, result.Rate,
+			result.Throughput, formatDuration(latency.Median), formatDuration(latency.P90), formatDuration(latency.P99),
+			formatDuration(latency.Max), formatDuration(result.Service.Median), formatDuration(result.Service.P99), saturated)
+	}
+
+	return w.Flush()
+}
+
+// formatDuration formats the duration in milliseconds.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1f ms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+func runCommand(args []string) error {
+
+	var backends string
+	var scenarios string
+	var out string
+	var csvOut string
+	var jsonOutput bool
+	var rates string
+	var oracle bool
+
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+
+	config, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	fs.StringVar(&backends, "backend", strings.Join(backendNames, ","), "comma separated list of backends to run")
+	fs.StringVar(&scenarios, "scenario", "", "comma separated list of scenarios (name, ID or number) to run, all by default")
+	fs.StringVar(&out, "out", "", "file to write the results to (JSON), dbbench-<start time>.json by default")
+	fs.StringVar(&csvOut, "csv", "", "file to write the results to (CSV)")
+	fs.BoolVar(&jsonOutput, "json", false, "print the results as JSON instead of a table")
+	fs.IntVar(&config.Run.Iterations, "iterations", config.Run.Iterations, "number of measured runs of every scenario")
+	fs.IntVar(&config.Run.Warmup, "warmup", config.Run.Warmup, "number of unmeasured runs of every scenario before the measured ones")
+	fs.IntVar(&config.Run.Workers, "workers", config.Run.Workers, "number of concurrent workers with own connections running the read/write scenarios, sequential if 0")
+	fs.IntVar(&config.Run.Batch, "batch", config.Run.Batch, "number of entries of one operation of a bulk scenario run by workers")
+	fs.IntVar(&config.Run.Seed, "seed", config.Run.Seed, "seed of the generated data, the same seed generates the same data for every backend")
+	fs.StringVar(&rates, "rate", "", "comma separated list of target rates (operations per second) to run the read/write scenarios at (open-loop), one sweep step per rate")
+	fs.BoolVar(&oracle, "oracle", true, "check the answers of the backends against the in-memory backend, slows the verification down, off for backends holding pre-populated data (-oracle=false to skip)")
+	registerBackendFlags(fs, &config)
+	registerPayloadFlags(fs, &config)
+	fs.StringVar(&config.Access.Distribution, "access", config.Access.Distribution, "distribution of the keys of the read/update scenarios: sequential, uniform, zipfian, latest or hotspot")
+	fs.Float64Var(&config.Access.Zipfian, "zipfian", config.Access.Zipfian, "constant of the zipfian and latest distributions, between 0 and 1")
+	fs.IntVar(&config.Access.HotspotKeys, "hotspot-keys", config.Access.HotspotKeys, "percentage of the entries forming the hot spot")
+	fs.IntVar(&config.Access.HotspotOps, "hotspot-ops", config.Access.HotspotOps, "percentage of the operations hitting the hot spot")
+	fs.Parse(args)
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	config.ConfigureScenarios()
+
+	sweep, err := parseRates(rates)
+	if err != nil {
+		return err
+	}
+
+	selected, err := dbBench.SelectScenarios(splitList(scenarios))
+	if err != nil {
+		return err
+	}
+
+	report := dbBench.Report{Metadata: dbBench.NewMetadata(config.Run.Warmup, config.Run.Iterations)}
+	report.Metadata.Workers = config.Run.Workers
+	report.Metadata.Rates = sweep
+	report.Metadata.Seed = config.Run.Seed
+	report.Metadata.Payload = config.Payload
+	report.Metadata.Access = config.Access
+	ctx := context.Background()
+
+	// A failed backend does not stop the others, its error is recorded and the report written anyway.
+	for _, name := range splitList(backends) {
+		results, err := runBackend(ctx, config, name, selected, sweep, oracle)
+		report.Results = append(report.Results, results...)
+		if err != nil {
+			log.Error().Err(err).Str("backend", name).Msg("backend failed")
+			if report.Metadata.BackendErrors == nil {
+				report.Metadata.BackendErrors = make(map[string]string)
+			}
+			report.Metadata.BackendErrors[name] = err.Error()
+		}
+	}
+
+	report.Metadata.Finished = time.Now()
+
+	if out == "" {
+		out = fmt.Sprintf("dbbench-%s.json", report.Metadata.Started.Format("20060102-150405"))
+	}
+
+	if err := writeReport(out, report); err != nil {
+		return err
+	}
+
+	log.Info().Str("file", out).Msg("results written")
+
+	if csvOut != "" {
+		if err := writeCSV(csvOut, report); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput {
+		if err := dbBench.WriteReport(os.Stdout, report); err != nil {
+			return err
+		}
+	} else if err := printResults(os.Stdout, report.Results); err != nil {
+		return err
+	} else if len(sweep) > 0 {
+		fmt.Println()
+		if err := printSweep(os.Stdout, report.Results); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, result := range report.Results {
+		if result.Status == dbBench.StatusFailed {
+			failed++
+		}
+	}
+
+	if len(report.Metadata.BackendErrors) > 0 {
+		return errors.Errorf("%d backends failed, %d scenarios failed", len(report.Metadata.BackendErrors), failed)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d scenarios failed", failed)
+	}
+
+	return nil
+}
+
+// runBackend measures the scenarios against one backend and removes the data they created. Every worker gets
+// its own connection to the backend. The scenarios are run once per rate of the sweep. The answers are checked
+// against the in-memory backend if oracle is set and the backend holds no pre-populated data.
+func runBackend(ctx context.Context, config dbBench.Config, name string, scenarios []dbBench.Scenario, sweep []float64, oracle bool) ([]dbBench.Result, error) {
+
+	backend, err := openBackend(config, name)
+	if err != nil {
+		return nil, err
+	}
+	defer backend.Close()
+
+	bench, err := dbBench.NewBench(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	bench.BatchSize = config.Run.Batch
+	bench.Seed = int64(config.Run.Seed)
+	bench.Payload = config.Payload
+	bench.Access = config.Access
+
+	// The oracle knows the data created by the scenarios only, the answers over pre-populated data differ.
+	if oracle && bench.StaticArtifactCount > 0 {
+		log.Warn().Str("backend", name).Msg("oracle disabled, the backend holds pre-populated data")
+	} else if oracle {
+		bench.Oracle = dbBench.NewMemoryBackend()
+	}
+
+	for i := 0; i < config.Run.Workers; i++ {
+		if sharedBackend(name) {
+			bench.Workers = append(bench.Workers, backend)
+			continue
+		}
+		worker, err := openBackend(config, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed connecting worker %d", i)
+		}
+		defer worker.Close()
+		bench.Workers = append(bench.Workers, worker)
+	}
+
+	log.Info().Str("backend", name).Int("artifacts", bench.StaticArtifactCount).Msg("pre-populated data")
+
+	if len(sweep) == 0 {
+		sweep = []float64{0}
+	}
+
+	var results []dbBench.Result
+
+	for _, rate := range sweep {
+		bench.Rate = rate
+
+		for _, scenario := range scenarios {
+			samples, err := bench.Measure(ctx, scenario, config.Run.Warmup, config.Run.Iterations)
+			result := bench.Result(scenario, samples, err)
+			results = append(results, result)
+
+			event := log.Info()
+			if result.Status == dbBench.StatusFailed {
+				event = log.Warn()
+			}
+			event.Str("backend", name).Str("scenario", scenario.ID()).Str("status", string(result.Status)).
+				Int64("median_ms", result.Stats.Median.Milliseconds()).Str("error", result.Error).Msg("scenario finished")
+		}
+	}
+
+	if err := bench.Close(ctx); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// parseRates parses the comma separated list of positive rates.
+func parseRates(s string) ([]float64, error) {
+	var rates []float64
+	for _, item := range splitList(s) {
+		rate, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rate %s", item)
+		}
+		if rate <= 0 {
+			return nil, errors.Errorf("rate %s is not positive", item)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// registerPayloadFlags overrides the payload of the generated artifacts, so the scenarios can be run at several
+// document sizes.
+func registerPayloadFlags(fs *flag.FlagSet, config *dbBench.Config) {
+	fs.IntVar(&config.Payload.Fields, "payload-fields", config.Payload.Fields, "number of extra text fields of every artifact")
+	fs.IntVar(&config.Payload.Depth, "payload-depth", config.Payload.Depth, "depth of a nested object of every artifact")
+	fs.IntVar(&config.Payload.Array, "payload-array", config.Payload.Array, "length of an array of numbers of every artifact")
+	fs.IntVar(&config.Payload.Text, "payload-text", config.Payload.Text, "size in bytes of a text field of every artifact")
+	fs.IntVar(&config.Payload.Binary, "payload-binary", config.Payload.Binary, "size in bytes of a binary field of every artifact")
+	fs.StringVar(&config.Payload.Distribution, "payload-distribution", config.Payload.Distribution, "distribution of the text and binary sizes: fixed, uniform or exponential")
+}
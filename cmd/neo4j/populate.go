@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	dbBench "github.com/geomodular/db-bench"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+	"time"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Error().Err(err).Msg("")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func run() error {
+
+	configPath := dbBench.ConfigPath(os.Args[1:])
+	config, err := dbBench.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	flag.String("config", configPath, "YAML config file, $"+dbBench.ConfigEnv+" by default")
+	flag.StringVar(&config.Neo4j.Endpoint, "endpoint", config.Neo4j.Endpoint, "Neo4j endpoint")
+	flag.StringVar(&config.Neo4j.Username, "username", config.Neo4j.Username, "Neo4j username")
+	flag.StringVar(&config.Neo4j.Password, "password", config.Neo4j.Password, "Neo4j password")
+	flag.IntVar(&config.Populate.N, "n", config.Populate.N, "the number of entries to generate inside DB")
+	flag.IntVar(&config.Populate.Chunk, "chunk", config.Populate.Chunk, "maximum inserts of one bulk operation")
+	flag.StringVar(&config.Populate.Topology, "topology", config.Populate.Topology, "topology of the edges: none, random, preferential, forest or chains")
+	flag.IntVar(&config.Populate.Edges, "edges", config.Populate.Edges, "the number of edges to generate inside DB")
+	flag.IntVar(&config.Populate.Workers, "workers", config.Populate.Workers, "the number of concurrent writers")
+	flag.StringVar(&config.Populate.Checkpoint, "checkpoint", config.Populate.Checkpoint, "directory of the checkpoints resuming an interrupted run")
+	flag.IntVar(&config.Populate.Seed, "seed", config.Populate.Seed, "seed of the generated data, the same seed populates every database with the same data")
+	flag.IntVar(&config.Payload.Fields, "payload-fields", config.Payload.Fields, "number of extra text fields of every entry")
+	flag.IntVar(&config.Payload.Depth, "payload-depth", config.Payload.Depth, "depth of a nested object of every entry")
+	flag.IntVar(&config.Payload.Array, "payload-array", config.Payload.Array, "length of an array of numbers of every entry")
+	flag.IntVar(&config.Payload.Text, "payload-text", config.Payload.Text, "size in bytes of a text field of every entry")
+	flag.IntVar(&config.Payload.Binary, "payload-binary", config.Payload.Binary, "size in bytes of a binary field of every entry")
+	flag.StringVar(&config.Payload.Distribution, "payload-distribution", config.Payload.Distribution, "distribution of the text and binary sizes: fixed, uniform or exponential")
+	flag.Parse()
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	n := config.Populate.N
+
+	// The backend creates the key index, counts the entities and removes the unfinished bulks.
+	backend, err := dbBench.NewNeo4jBackend(config.Neo4j.Endpoint, config.Neo4j.Username, config.Neo4j.Password)
+	if err != nil {
+		return errors.Wrap(err, "failed initializing neo4j backend")
+	}
+	defer backend.Close()
+
+	driver, err := neo4j.NewDriver(config.Neo4j.Endpoint, neo4j.BasicAuth(config.Neo4j.Username, config.Neo4j.Password, ""))
+	if err != nil {
+		return errors.Wrap(err, "failed creating neo4j driver")
+	}
+	defer driver.Close()
+
+	populator := dbBench.Populator{
+		Backend: backend,
+		Write: func(_ context.Context, dataset dbBench.Dataset) error {
+			// Sessions are not safe for concurrent use, every bulk has its own.
+			session := driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+			defer session.Close()
+			return dbBench.CreateBulkNeo4jGraph(session, dataset)
+		},
+		Config:  config.Populate,
+		Payload: config.Payload,
+		Progress: func(p dbBench.PopulateProgress) {
+			log.Info().
+				Int("stored", p.Stored).
+				Int("count", p.Rows).
+				Int("edges", p.Edges).
+				Float64("perc", (float64(p.Rows+p.Stored)/float64(n))*100.0).
+				Float64("rows_per_sec", p.RowsPerSec).
+				Str("eta", p.ETA.Round(time.Second).String()).
+				Msg("status")
+		},
+	}
+
+	return populator.Run(context.Background())
+}
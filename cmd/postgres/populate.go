@@ -1,14 +1,13 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"flag"
-	"fmt"
 	dbBench "github.com/geomodular/db-bench"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"os"
+	"time"
 )
 
 func main() {
@@ -21,69 +20,66 @@ func main() {
 
 func run() error {
 
-	var postgresConnStr string
-	var n int
-	var chunk int
-
-	flag.StringVar(&postgresConnStr, "host", dbBench.PostgresConnStr, "connection string to PostgreSQL")
-	flag.IntVar(&n, "n", 1000000, "the number of entries to generate inside DB")
-	flag.IntVar(&chunk, "chunk", 10000, "maximum inserts of one bulk operation")
-	flag.Parse()
-
-	db, err := dbBench.InitPostgres(postgresConnStr)
-	if err != nil {
-		return errors.Wrap(err, "failed initializing postgres connection")
-	}
-
-	if err := dbBench.CreatePostgresTestingTables(db); err != nil {
-		return errors.Wrap(err, "failed creating postgres testing tables")
-	}
-
-	var rows int
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&rows)
+	configPath := dbBench.ConfigPath(os.Args[1:])
+	config, err := dbBench.LoadConfig(configPath)
 	if err != nil {
-		return errors.Wrap(err, "failed counting rows")
+		return err
 	}
 
-	if rows >= n {
-		return errors.New("db is already populated")
-	}
-
-	log.Info().Int("rows", rows).Msg("pre-feeding status")
-
-	total := n - rows
+	flag.String("config", configPath, "YAML config file, $"+dbBench.ConfigEnv+" by default")
+	flag.StringVar(&config.Postgres.ConnStr, "host", config.Postgres.ConnStr, "connection string to PostgreSQL")
+	flag.IntVar(&config.Populate.N, "n", config.Populate.N, "the number of entries to generate inside DB")
+	flag.IntVar(&config.Populate.Chunk, "chunk", config.Populate.Chunk, "maximum inserts of one bulk operation")
+	flag.StringVar(&config.Populate.Topology, "topology", config.Populate.Topology, "topology of the edges: none, random, preferential, forest or chains")
+	flag.IntVar(&config.Populate.Edges, "edges", config.Populate.Edges, "the number of edges to generate inside DB")
+	flag.IntVar(&config.Populate.Workers, "workers", config.Populate.Workers, "the number of concurrent writers")
+	flag.StringVar(&config.Populate.Checkpoint, "checkpoint", config.Populate.Checkpoint, "directory of the checkpoints resuming an interrupted run")
+	flag.IntVar(&config.Populate.Seed, "seed", config.Populate.Seed, "seed of the generated data, the same seed populates every database with the same data")
+	flag.IntVar(&config.Payload.Fields, "payload-fields", config.Payload.Fields, "number of extra text fields of every entry")
+	flag.IntVar(&config.Payload.Depth, "payload-depth", config.Payload.Depth, "depth of a nested object of every entry")
+	flag.IntVar(&config.Payload.Array, "payload-array", config.Payload.Array, "length of an array of numbers of every entry")
+	flag.IntVar(&config.Payload.Text, "payload-text", config.Payload.Text, "size in bytes of a text field of every entry")
+	flag.IntVar(&config.Payload.Binary, "payload-binary", config.Payload.Binary, "size in bytes of a binary field of every entry")
+	flag.StringVar(&config.Payload.Distribution, "payload-distribution", config.Payload.Distribution, "distribution of the text and binary sizes: fixed, uniform or exponential")
+	flag.Parse()
 
-	actual := 0
-	for actual < total {
-		bulkCount := chunk
-		if actual+bulkCount > total {
-			bulkCount = total - actual
-		}
-		if err := createBulkPostgresArtifacts(db, bulkCount); err != nil {
-			return errors.Wrap(err, "failed creating artifacts")
-		}
-		actual += bulkCount
-		log.Info().Int("count", actual).Float64("perc", (float64(actual+rows)/float64(n))*100.0).Msg("status")
+	if err := config.Validate(); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func createBulkPostgresArtifacts(db *sql.DB, n int) error {
+	n := config.Populate.N
 
-	var stmt string
-
-	for i := 0; i < n; i++ {
-		id, _ := uuid.NewUUID()
-		name := fmt.Sprintf("name-%d", i)
-		description := fmt.Sprintf("description-%d", i)
-		stmt = stmt + fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', '%s', '%s');", id, name, description)
+	db, err := dbBench.InitPostgres(config.Postgres.ConnStr)
+	if err != nil {
+		return errors.Wrap(err, "failed initializing postgres connection")
 	}
+	defer db.Close()
 
-	_, err := db.Exec(stmt)
+	// The backend creates the testing tables, counts the rows and removes the unfinished bulks.
+	backend, err := dbBench.NewPostgresBackend(config.Postgres.ConnStr)
 	if err != nil {
-		return errors.Wrap(err, "failed inserting into table")
+		return errors.Wrap(err, "failed initializing postgres backend")
+	}
+	defer backend.Close()
+
+	populator := dbBench.Populator{
+		Backend: backend,
+		Write: func(_ context.Context, dataset dbBench.Dataset) error {
+			return dbBench.CreateBulkPostgresGraph(db, dataset)
+		},
+		Config:  config.Populate,
+		Payload: config.Payload,
+		Progress: func(p dbBench.PopulateProgress) {
+			log.Info().
+				Int("stored", p.Stored).
+				Int("count", p.Rows).
+				Int("edges", p.Edges).
+				Float64("perc", (float64(p.Rows+p.Stored)/float64(n))*100.0).
+				Float64("rows_per_sec", p.RowsPerSec).
+				Str("eta", p.ETA.Round(time.Second).String()).
+				Msg("status")
+		},
 	}
 
-	return nil
+	return populator.Run(context.Background())
 }
@@ -0,0 +1,195 @@
+package db_bench
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Verdict is the outcome of the comparison of one scenario between two runs.
+type Verdict string
+
+const (
+	VerdictSame         Verdict = "same"
+	VerdictImprovement  Verdict = "improvement"
+	VerdictRegression   Verdict = "regression"
+	VerdictBroken       Verdict = "broken"
+	VerdictIncomparable Verdict = "incomparable"
+)
+
+// Comparison compares the results of one scenario on one backend between a base and a new run.
+type Comparison struct {
+	Backend  string
+	Num      int
+	Scenario string
+
+	Base Result
+	New  Result
+
+	// Relative change of the median, e.g. 0.1 if the new run is 10 % slower.
+	Delta float64
+
+	// Probability the iteration samples of both runs come from the same distribution.
+	PValue float64
+
+	Verdict Verdict
+}
+
+// Compare matches the results of both runs by backend, scenario and rate and compares them. A change is
+// reported as a regression or an improvement only if it is statistically significant (p-value below alpha)
+// and the median changed more than the threshold (relative, e.g. 0.1 for 10 %). A scenario passing in the
+// base run and failing in the new one is reported as broken. Scenarios with too few iterations to reach a
+// p-value below alpha (less than 4 per run for 0.05) are incomparable. Results missing in one of the runs are
+// left out.
+func Compare(base, current []Result, threshold, alpha float64) []Comparison {
+
+	type key struct {
+		backend  string
+		scenario string
+		rate     float64
+	}
+
+	baseResults := make(map[key]Result)
+	for _, result := range base {
+		baseResults[key{result.Backend, result.Scenario, result.Rate}] = result
+	}
+
+	var comparisons []Comparison
+
+	for _, newResult := range current {
+		baseResult, ok := baseResults[key{newResult.Backend, newResult.Scenario, newResult.Rate}]
+		if !ok {
+			continue
+		}
+		comparisons = append(comparisons, compareResults(baseResult, newResult, threshold, alpha))
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		if comparisons[i].Backend != comparisons[j].Backend {
+			return comparisons[i].Backend < comparisons[j].Backend
+		}
+		return comparisons[i].Num < comparisons[j].Num
+	})
+
+	return comparisons
+}
+
+func compareResults(base, current Result, threshold, alpha float64) Comparison {
+
+	comparison := Comparison{
+		Backend:  current.Backend,
+		Num:      current.Num,
+		Scenario: current.Scenario,
+		Base:     base,
+		New:      current,
+		PValue:   1,
+		Verdict:  VerdictIncomparable,
+	}
+
+	if base.Status == StatusOK && current.Status == StatusFailed {
+		comparison.Verdict = VerdictBroken
+		return comparison
+	}
+
+	if base.Status != StatusOK || current.Status != StatusOK || base.Stats.Median == 0 {
+		return comparison
+	}
+
+	comparison.Delta = float64(current.Stats.Median-base.Stats.Median) / float64(base.Stats.Median)
+	comparison.PValue = MannWhitneyU(base.Iterations, current.Iterations)
+
+	// Too few iterations can not prove any change, reporting them as the same would hide it.
+	if minPValue(len(base.Iterations), len(current.Iterations)) >= alpha {
+		return comparison
+	}
+
+	comparison.Verdict = VerdictSame
+
+	if comparison.PValue < alpha {
+		switch {
+		case comparison.Delta > threshold:
+			comparison.Verdict = VerdictRegression
+		case comparison.Delta < -threshold:
+			comparison.Verdict = VerdictImprovement
+		}
+	}
+
+	return comparison
+}
+
+// minPValue returns the smallest p-value MannWhitneyU can return for samples of the sizes, the one of fully
+// separated samples without ties.
+func minPValue(n1, n2 int) float64 {
+
+	if n1 < 2 || n2 < 2 {
+		return 1
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * (n + 1)
+
+	return math.Erfc((mean - 0.5) / math.Sqrt(variance) / math.Sqrt2)
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of the samples. The test does not
+// assume normally distributed samples, which latencies rarely are. The p-value is approximated by the normal
+// distribution with the tie and continuity corrections, it is 1 if any of the samples has less than two
+// values.
+func MannWhitneyU(x, y []time.Duration) float64 {
+
+	n1, n2 := len(x), len(y)
+	if n1 < 2 || n2 < 2 {
+		return 1
+	}
+
+	type sample struct {
+		value time.Duration
+		first bool
+	}
+
+	samples := make([]sample, 0, n1+n2)
+	for _, v := range x {
+		samples = append(samples, sample{v, true})
+	}
+	for _, v := range y {
+		samples = append(samples, sample{v, false})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// Rank the samples, ties get the average of their ranks.
+	var rankSum, tieSum float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].first {
+				rankSum += rank
+			}
+		}
+
+		t := float64(j - i)
+		tieSum += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := rankSum - float64(n1*(n1+1))/2
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieSum/(n*(n-1)))
+
+	if variance == 0 {
+		return 1
+	}
+
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+
+	return math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,91 @@
+package db_bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func milliseconds(values ...int) []Timing {
+	timings := make([]Timing, len(values))
+	for i, v := range values {
+		timings[i] = Timing{Measured: time.Duration(v) * time.Millisecond}
+	}
+	return timings
+}
+
+func TestMannWhitneyU(t *testing.T) {
+
+	x := []time.Duration{1, 2, 3, 4, 5}
+	y := []time.Duration{6, 7, 8, 9, 10}
+
+	assert.InDelta(t, 0.012, MannWhitneyU(x, y), 0.001)
+	assert.InDelta(t, 0.012, MannWhitneyU(y, x), 0.001)
+	assert.Equal(t, 1.0, MannWhitneyU(x, x))
+	assert.Equal(t, 1.0, MannWhitneyU([]time.Duration{1}, y))
+	assert.Equal(t, 1.0, MannWhitneyU([]time.Duration{1, 1}, []time.Duration{1, 1}))
+
+	assert.Equal(t, MannWhitneyU(x, y), minPValue(5, 5))
+	assert.Equal(t, 1.0, minPValue(1, 5))
+}
+
+func TestCompare(t *testing.T) {
+
+	create, _ := FindScenario("Create10")
+	read, _ := FindScenario("Read10000")
+	update, _ := FindScenario("Update10000")
+	query, _ := FindScenario("QueryRead10000")
+
+	base := []Result{
+		NewResult("arangodb", create, milliseconds(10, 11, 10, 12, 11), nil),
+		NewResult("arangodb", read, milliseconds(100, 101, 99, 100, 102), nil),
+		NewResult("arangodb", update, milliseconds(50, 51, 50, 52, 50), nil),
+		NewResult("arangodb", query, milliseconds(20, 21, 20, 22, 20), nil),
+	}
+	current := []Result{
+		NewResult("arangodb", create, milliseconds(20, 21, 20, 22, 21), nil),
+		NewResult("arangodb", read, milliseconds(100, 102, 99, 101, 100), nil),
+		NewResult("arangodb", update, nil, errors.New("timeout")),
+		NewResult("arangodb", query, milliseconds(10, 11, 10, 12, 10), nil),
+	}
+
+	comparisons := Compare(base, current, 0.1, 0.05)
+	require.Len(t, comparisons, 4)
+
+	assert.Equal(t, VerdictRegression, comparisons[0].Verdict)
+	assert.InDelta(t, 0.909, comparisons[0].Delta, 0.001)
+	assert.Equal(t, VerdictSame, comparisons[1].Verdict)
+	assert.Equal(t, VerdictBroken, comparisons[2].Verdict)
+	assert.Equal(t, VerdictImprovement, comparisons[3].Verdict)
+}
+
+func TestCompareNotSignificant(t *testing.T) {
+
+	create, _ := FindScenario("Create10")
+
+	// A single iteration can not prove any change.
+	base := []Result{NewResult("postgres", create, milliseconds(10), nil)}
+	current := []Result{NewResult("postgres", create, milliseconds(100), nil)}
+
+	comparisons := Compare(base, current, 0.1, 0.05)
+	require.Len(t, comparisons, 1)
+	assert.Equal(t, VerdictIncomparable, comparisons[0].Verdict)
+
+	// Neither can three, even fully separated.
+	base = []Result{NewResult("postgres", create, milliseconds(10, 11, 12), nil)}
+	current = []Result{NewResult("postgres", create, milliseconds(100, 101, 102), nil)}
+
+	comparisons = Compare(base, current, 0.1, 0.05)
+	require.Len(t, comparisons, 1)
+	assert.Equal(t, VerdictIncomparable, comparisons[0].Verdict)
+
+	base = []Result{NewResult("postgres", create, milliseconds(10, 11, 12, 13), nil)}
+	current = []Result{NewResult("postgres", create, milliseconds(100, 101, 102, 103), nil)}
+
+	comparisons = Compare(base, current, 0.1, 0.05)
+	require.Len(t, comparisons, 1)
+	assert.Equal(t, VerdictRegression, comparisons[0].Verdict)
+}
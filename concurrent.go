@@ -0,0 +1,175 @@
+package db_bench
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// concurrentBatchSize is the default number of entries handled by one operation of a bulk scenario run by
+// workers.
+const concurrentBatchSize = 1000
+
+// Ops is the measured phase of a scenario split into independent operations. The operations of a run can be
+// executed in any order and concurrently, every one on the backend of the worker executing it.
+type Ops struct {
+
+	// Count is the number of operations.
+	Count int
+
+	// Run executes the i-th operation.
+	Run func(ctx context.Context, backend Backend, i int) error
+}
+
+// singleOps creates n operations handling one entry each.
+func singleOps(n int, run func(ctx context.Context, backend Backend, i int) error) *Ops {
+	return &Ops{Count: n, Run: run}
+}
+
+// batchOps splits n entries into operations of size entries. The run function gets the range of the entries
+// of an operation.
+func batchOps(n, size int, run func(ctx context.Context, backend Backend, from, to int) error) *Ops {
+	return &Ops{
+		Count: (n + size - 1) / size,
+		Run: func(ctx context.Context, backend Backend, i int) error {
+			from := i * size
+			to := from + size
+			if to > n {
+				to = n
+			}
+			return run(ctx, backend, from, to)
+		},
+	}
+}
+
+// runOps executes the operations by the workers, every worker runs one operation at a time on its own
+// backend. It returns the latencies of all operations. The first failed operation stops all workers.
+func runOps(ctx context.Context, workers []Backend, ops *Ops) ([]time.Duration, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan int)
+	latencies := make([]time.Duration, ops.Count)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(backend Backend) {
+			defer wg.Done()
+			for i := range queue {
+				start := time.Now()
+				err := ops.Run(ctx, backend, i)
+				latencies[i] = time.Since(start)
+				if err != nil {
+					once.Do(func() {
+						firstErr = errors.Wrapf(err, "operation %d failed", i)
+						cancel()
+					})
+				}
+			}
+		}(worker)
+	}
+
+feed:
+	for i := 0; i < ops.Count; i++ {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return latencies, ctx.Err()
+}
+
+// scheduledOp is an operation waiting for a worker since its intended start.
+type scheduledOp struct {
+	i        int
+	intended time.Time
+}
+
+// runOpsAtRate issues the operations at the fixed rate (operations per second) no matter how fast the workers
+// are (open-loop). An operation waits in a queue if all workers are busy. Its latency is measured from its
+// intended start, so the waiting counts, which corrects the coordinated omission of closed-loop workers. It
+// returns the histograms of the latencies and of the service times (without the waiting).
+func runOpsAtRate(ctx context.Context, workers []Backend, ops *Ops, rate float64) (*Histogram, *Histogram, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The queue never blocks the schedule.
+	queue := make(chan scheduledOp, ops.Count)
+	latencies := make([]*Histogram, len(workers))
+	services := make([]*Histogram, len(workers))
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for w, worker := range workers {
+		latencies[w] = NewHistogram()
+		services[w] = NewHistogram()
+
+		wg.Add(1)
+		go func(backend Backend, latency, service *Histogram) {
+			defer wg.Done()
+			for op := range queue {
+				start := time.Now()
+				err := ops.Run(ctx, backend, op.i)
+				end := time.Now()
+				latency.Record(end.Sub(op.intended))
+				service.Record(end.Sub(start))
+				if err != nil {
+					once.Do(func() {
+						firstErr = errors.Wrapf(err, "operation %d failed", op.i)
+						cancel()
+					})
+				}
+			}
+		}(worker, latencies[w], services[w])
+	}
+
+	interval := time.Duration(float64(time.Second) / rate)
+	start := time.Now()
+
+schedule:
+	for i := 0; i < ops.Count; i++ {
+		intended := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(intended); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				break schedule
+			}
+		}
+		queue <- scheduledOp{i: i, intended: intended}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	latency, service := NewHistogram(), NewHistogram()
+	for w := range workers {
+		latency.Merge(latencies[w])
+		service.Merge(services[w])
+	}
+
+	return latency, service, ctx.Err()
+}
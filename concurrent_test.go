@@ -0,0 +1,91 @@
+package db_bench
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOps(t *testing.T) {
+
+	var mu sync.Mutex
+	done := make(map[int]int)
+
+	ops := singleOps(100, func(_ context.Context, _ Backend, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		done[i]++
+		return nil
+	})
+
+	latencies, err := runOps(context.Background(), make([]Backend, 4), ops)
+	require.NoError(t, err)
+	assert.Len(t, latencies, 100)
+	assert.Len(t, done, 100)
+	for i, n := range done {
+		assert.Equal(t, 1, n, "operation %d", i)
+	}
+}
+
+func TestRunOpsError(t *testing.T) {
+
+	ops := singleOps(100, func(_ context.Context, _ Backend, i int) error {
+		if i == 10 {
+			return ErrNotImplemented
+		}
+		return nil
+	})
+
+	_, err := runOps(context.Background(), make([]Backend, 4), ops)
+	assert.ErrorIs(t, err, ErrNotImplemented)
+}
+
+func TestBatchOps(t *testing.T) {
+
+	var ranges [][2]int
+	ops := batchOps(2500, 1000, func(_ context.Context, _ Backend, from, to int) error {
+		ranges = append(ranges, [2]int{from, to})
+		return nil
+	})
+
+	require.Equal(t, 3, ops.Count)
+	for i := 0; i < ops.Count; i++ {
+		require.NoError(t, ops.Run(context.Background(), nil, i))
+	}
+	assert.Equal(t, [][2]int{{0, 1000}, {1000, 2000}, {2000, 2500}}, ranges)
+}
+
+func TestRunOpsAtRate(t *testing.T) {
+
+	// One worker needs 2 ms per operation, but they are issued every 1 ms: the waiting for the worker is counted
+	// in the latency, not in the service time.
+	ops := singleOps(50, func(_ context.Context, _ Backend, _ int) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+
+	latency, service, err := runOpsAtRate(context.Background(), make([]Backend, 1), ops, 1000)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(50), latency.Count())
+	assert.Equal(t, int64(50), service.Count())
+	assert.Less(t, service.Percentile(50), 10*time.Millisecond)
+	assert.Greater(t, latency.Percentile(99), 40*time.Millisecond)
+}
+
+func TestRunOpsAtRateError(t *testing.T) {
+
+	ops := singleOps(10, func(_ context.Context, _ Backend, i int) error {
+		if i == 3 {
+			return ErrNotImplemented
+		}
+		return nil
+	})
+
+	_, _, err := runOpsAtRate(context.Background(), make([]Backend, 2), ops, 10000)
+	assert.ErrorIs(t, err, ErrNotImplemented)
+}
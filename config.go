@@ -0,0 +1,307 @@
+package db_bench
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnv names the environment variable with the path to the config file.
+const ConfigEnv = "DBBENCH_CONFIG"
+
+// Config holds the settings shared by the runner, the populate commands and the test suites. The values are
+// taken from DefaultConfig, overridden by a YAML file and then by the environment variables listed in the
+// `env` tags.
+type Config struct {
+	Arango   ArangoConfig   `yaml:"arango"`
+	Postgres PostgresConfig `yaml:"postgres"`
+	Neo4j    Neo4jConfig    `yaml:"neo4j"`
+	SQLite   SQLiteConfig   `yaml:"sqlite"`
+	Bolt     BoltConfig     `yaml:"bbolt"`
+	Run      RunConfig      `yaml:"run"`
+	Populate PopulateConfig `yaml:"populate"`
+	Payload  PayloadConfig  `yaml:"payload"`
+	Access   AccessConfig   `yaml:"access"`
+
+	// Scenarios overrides the parameters (sizes) of the scenarios given by their names.
+	Scenarios map[string]Params `yaml:"scenarios"`
+}
+
+// ArangoConfig is the connection to ArangoDB.
+type ArangoConfig struct {
+	Endpoint           string `yaml:"endpoint" env:"DBBENCH_ARANGO_ENDPOINT"`
+	Database           string `yaml:"database" env:"DBBENCH_ARANGO_DATABASE"`
+	DocumentCollection string `yaml:"document_collection" env:"DBBENCH_ARANGO_DOCUMENT_COLLECTION"`
+	EdgeCollection     string `yaml:"edge_collection" env:"DBBENCH_ARANGO_EDGE_COLLECTION"`
+}
+
+// PostgresConfig is the connection to PostgreSQL.
+type PostgresConfig struct {
+	ConnStr string `yaml:"conn" env:"DBBENCH_POSTGRES_CONN"`
+}
+
+// Neo4jConfig is the connection to Neo4j.
+type Neo4jConfig struct {
+	Endpoint string `yaml:"endpoint" env:"DBBENCH_NEO4J_ENDPOINT"`
+	Username string `yaml:"username" env:"DBBENCH_NEO4J_USERNAME"`
+	Password string `yaml:"password" env:"DBBENCH_NEO4J_PASSWORD"`
+}
+
+// SQLiteConfig is the SQLite database file, it is created if it does not exist.
+type SQLiteConfig struct {
+	Path string `yaml:"path" env:"DBBENCH_SQLITE_PATH"`
+}
+
+// BoltConfig is the bbolt database file, it is created if it does not exist.
+type BoltConfig struct {
+	Path string `yaml:"path" env:"DBBENCH_BOLT_PATH"`
+}
+
+// RunConfig controls how the scenarios are measured.
+type RunConfig struct {
+	Warmup     int `yaml:"warmup" env:"DBBENCH_WARMUP"`
+	Iterations int `yaml:"iterations" env:"DBBENCH_ITERATIONS"`
+	Workers    int `yaml:"workers" env:"DBBENCH_WORKERS"`
+
+	// Batch is the number of entries of one operation of a bulk scenario run by workers.
+	Batch int `yaml:"batch" env:"DBBENCH_BATCH"`
+
+	// Seed of the data generated by the scenarios, every backend of a run gets the same data.
+	Seed int `yaml:"seed" env:"DBBENCH_SEED"`
+}
+
+// PopulateConfig controls the pre-population of the databases.
+type PopulateConfig struct {
+	N     int `yaml:"n" env:"DBBENCH_POPULATE_N"`
+	Chunk int `yaml:"chunk" env:"DBBENCH_POPULATE_CHUNK"`
+
+	// Topology of the edges connecting the entries (see Topologies) and their total number.
+	Topology string `yaml:"topology" env:"DBBENCH_POPULATE_TOPOLOGY"`
+	Edges    int    `yaml:"edges" env:"DBBENCH_POPULATE_EDGES"`
+
+	// Workers is the number of concurrent writers. Checkpoint is the directory keeping the keys of the bulks
+	// being written, one subdirectory per database.
+	Workers    int    `yaml:"workers" env:"DBBENCH_POPULATE_WORKERS"`
+	Checkpoint string `yaml:"checkpoint" env:"DBBENCH_POPULATE_CHECKPOINT"`
+
+	// Seed of the generated data, the same seed populates every database with the same data.
+	Seed int `yaml:"seed" env:"DBBENCH_POPULATE_SEED"`
+}
+
+// PayloadConfig shapes the payload, the extra content of every artifact generated by the scenarios and the
+// populate commands. All sizes zero generate no payload.
+type PayloadConfig struct {
+
+	// Fields is the number of extra short text fields, Depth the depth of a nested object and Array the length
+	// of an array of numbers.
+	Fields int `yaml:"fields" env:"DBBENCH_PAYLOAD_FIELDS" json:"fields,omitempty"`
+	Depth  int `yaml:"depth" env:"DBBENCH_PAYLOAD_DEPTH" json:"depth,omitempty"`
+	Array  int `yaml:"array" env:"DBBENCH_PAYLOAD_ARRAY" json:"array,omitempty"`
+
+	// Text and Binary are the sizes in bytes of a text and a binary field, drawn from the Distribution (see
+	// PayloadDistributions).
+	Text         int    `yaml:"text" env:"DBBENCH_PAYLOAD_TEXT" json:"text,omitempty"`
+	Binary       int    `yaml:"binary" env:"DBBENCH_PAYLOAD_BINARY" json:"binary,omitempty"`
+	Distribution string `yaml:"distribution" env:"DBBENCH_PAYLOAD_DISTRIBUTION" json:"distribution"`
+}
+
+func (c PayloadConfig) empty() bool {
+	return c.Fields == 0 && c.Depth == 0 && c.Array == 0 && c.Text == 0 && c.Binary == 0
+}
+
+// AccessConfig is the access pattern of the read and update scenarios.
+type AccessConfig struct {
+
+	// Distribution of the keys (see KeyDistributions). The sequential one touches every entry created by the
+	// parent scenario once, the others draw the keys from all stored entries, the pre-populated ones included.
+	Distribution string `yaml:"distribution" env:"DBBENCH_ACCESS_DISTRIBUTION" json:"distribution"`
+
+	// Zipfian is the constant of the zipfian and latest distributions, from (0, 1), the higher the more skewed.
+	Zipfian float64 `yaml:"zipfian" env:"DBBENCH_ACCESS_ZIPFIAN" json:"zipfian,omitempty"`
+
+	// HotspotKeys is the percentage of the entries forming the hot spot and HotspotOps the percentage of the
+	// operations hitting it.
+	HotspotKeys int `yaml:"hotspot_keys" env:"DBBENCH_ACCESS_HOTSPOT_KEYS" json:"hotspot_keys,omitempty"`
+	HotspotOps  int `yaml:"hotspot_ops" env:"DBBENCH_ACCESS_HOTSPOT_OPS" json:"hotspot_ops,omitempty"`
+}
+
+// LoadConfig reads the configuration from the YAML file (if the path is not empty) and the environment.
+// Unknown keys in the file are reported as errors.
+func LoadConfig(path string) (Config, error) {
+
+	config := DefaultConfig()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "failed reading config file")
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil {
+			return Config{}, errors.Wrapf(err, "failed parsing config file %s", path)
+		}
+	}
+
+	if err := config.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// ConfigPath returns the path to the config file given by the `-config` argument or the DBBENCH_CONFIG
+// environment variable. The arguments are scanned before the flags are parsed, so the flags can default to the
+// configured values.
+func ConfigPath(args []string) string {
+
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config=")
+		}
+	}
+
+	return os.Getenv(ConfigEnv)
+}
+
+// Validate checks the numeric settings.
+func (c Config) Validate() error {
+
+	if c.Run.Iterations < 1 {
+		return errors.New("at least one iteration is required")
+	}
+
+	if c.Run.Warmup < 0 || c.Run.Workers < 0 {
+		return errors.New("number of warmup runs and workers can not be negative")
+	}
+
+	if c.Run.Batch < 1 || c.Populate.Chunk < 1 {
+		return errors.New("batch and chunk sizes have to be positive")
+	}
+
+	if !Topology(c.Populate.Topology).valid() {
+		return errors.Errorf("unknown topology %s", c.Populate.Topology)
+	}
+
+	if c.Populate.Edges < 0 {
+		return errors.New("number of edges can not be negative")
+	}
+
+	if c.Populate.Workers < 1 {
+		return errors.New("at least one populate worker is required")
+	}
+
+	payload := c.Payload
+	if payload.Fields < 0 || payload.Depth < 0 || payload.Array < 0 || payload.Text < 0 || payload.Binary < 0 {
+		return errors.New("payload sizes can not be negative")
+	}
+
+	if !PayloadDistribution(payload.Distribution).valid() {
+		return errors.Errorf("unknown payload distribution %s", payload.Distribution)
+	}
+
+	access := c.Access
+	if !KeyDistribution(access.Distribution).valid() {
+		return errors.Errorf("unknown key distribution %s", access.Distribution)
+	}
+
+	if access.Zipfian <= 0 || access.Zipfian >= 1 {
+		return errors.New("zipfian constant has to be between 0 and 1")
+	}
+
+	if access.HotspotKeys <= 0 || access.HotspotKeys > 100 || access.HotspotOps < 0 || access.HotspotOps > 100 {
+		return errors.New("hot spot percentages have to be between 0 and 100")
+	}
+
+	for name, params := range c.Scenarios {
+		scenario, ok := FindScenario(name)
+		if !ok {
+			return errors.Errorf("unknown scenario %s in config", name)
+		}
+		for param := range params {
+			if _, ok := scenario.Params[param]; !ok {
+				return errors.Errorf("scenario %s has no parameter %s", scenario.Name, param)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ConfigureScenarios overrides the parameters of the Scenarios by the config.
+func (c Config) ConfigureScenarios() {
+
+	for i, scenario := range Scenarios {
+		overrides, ok := c.Scenarios[scenario.Name]
+		if !ok {
+			continue
+		}
+
+		params := make(Params, len(scenario.Params))
+		for name, value := range scenario.Params {
+			params[name] = value
+		}
+		for name, value := range overrides {
+			params[name] = value
+		}
+		Scenarios[i].Params = params
+	}
+}
+
+// applyEnv overrides the settings by the environment variables named by the `env` tags of the fields.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+
+	sections := reflect.ValueOf(c).Elem()
+
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		if section.Kind() != reflect.Struct {
+			continue
+		}
+
+		for j := 0; j < section.NumField(); j++ {
+			env := section.Type().Field(j).Tag.Get("env")
+			value, ok := lookup(env)
+			if env == "" || !ok {
+				continue
+			}
+
+			field := section.Field(j)
+			switch field.Kind() {
+			case reflect.String:
+				field.SetString(value)
+			case reflect.Int:
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return errors.Wrapf(err, "invalid %s", env)
+				}
+				field.SetInt(int64(n))
+			case reflect.Float64:
+				f, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return errors.Wrapf(err, "invalid %s", env)
+				}
+				field.SetFloat(f)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package db_bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "dbbench.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	config, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), config)
+}
+
+func TestLoadConfigFileAndEnv(t *testing.T) {
+
+	path := writeConfig(t, `
+postgres:
+  conn: postgres://file
+neo4j:
+  password: secret
+run:
+  iterations: 5
+scenarios:
+  Create10:
+    n: 20
+`)
+
+	t.Setenv("DBBENCH_NEO4J_PASSWORD", "env-secret")
+	t.Setenv("DBBENCH_ITERATIONS", "7")
+	t.Setenv("DBBENCH_ACCESS_ZIPFIAN", "0.5")
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "postgres://file", config.Postgres.ConnStr)
+	assert.Equal(t, "env-secret", config.Neo4j.Password)
+	assert.Equal(t, 7, config.Run.Iterations)
+	assert.Equal(t, 0.5, config.Access.Zipfian)
+	assert.Equal(t, DefaultConfig().Arango, config.Arango)
+	assert.Equal(t, Params{"n": 20}, config.Scenarios["Create10"])
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+
+	_, err := LoadConfig(writeConfig(t, "postgres:\n  host: localhost\n"))
+	assert.Error(t, err, "unknown key")
+
+	_, err = LoadConfig(writeConfig(t, "scenarios:\n  Create11:\n    n: 1\n"))
+	assert.Error(t, err, "unknown scenario")
+
+	_, err = LoadConfig(writeConfig(t, "scenarios:\n  Create10:\n    m: 1\n"))
+	assert.Error(t, err, "unknown parameter")
+
+	_, err = LoadConfig(writeConfig(t, "run:\n  iterations: 0\n"))
+	assert.Error(t, err, "no iterations")
+
+	_, err = LoadConfig(writeConfig(t, "populate:\n  topology: star\n"))
+	assert.Error(t, err, "unknown topology")
+
+	_, err = LoadConfig(writeConfig(t, "payload:\n  text: 1000\n  distribution: normal\n"))
+	assert.Error(t, err, "unknown payload distribution")
+
+	_, err = LoadConfig(writeConfig(t, "access:\n  distribution: pareto\n"))
+	assert.Error(t, err, "unknown key distribution")
+
+	_, err = LoadConfig(writeConfig(t, "access:\n  zipfian: 1.5\n"))
+	assert.Error(t, err, "zipfian constant out of range")
+
+	t.Setenv("DBBENCH_WORKERS", "many")
+	_, err = LoadConfig("")
+	assert.Error(t, err, "invalid number")
+}
+
+func TestConfigureScenarios(t *testing.T) {
+
+	original := make([]Scenario, len(Scenarios))
+	copy(original, Scenarios)
+	defer copy(Scenarios, original)
+
+	config := DefaultConfig()
+	config.Scenarios = map[string]Params{"Create10": {"n": 20}}
+	config.ConfigureScenarios()
+
+	scenario, _ := FindScenario("Create10")
+	assert.Equal(t, Params{"n": 20}, scenario.Params)
+	assert.Equal(t, Params{"n": 10}, original[0].Params)
+}
+
+func TestConfigPath(t *testing.T) {
+
+	t.Setenv(ConfigEnv, "env.yaml")
+
+	assert.Equal(t, "a.yaml", ConfigPath([]string{"-backend", "postgres", "-config", "a.yaml"}))
+	assert.Equal(t, "b.yaml", ConfigPath([]string{"--config=b.yaml"}))
+	assert.Equal(t, "env.yaml", ConfigPath([]string{"-backend", "config"}))
+}
@@ -0,0 +1,239 @@
+package db_bench
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Edge is a backend independent view of an edge (relation) between two artifacts given by their keys.
+type Edge struct {
+	Key  string
+	From string
+	To   string
+	Body string
+}
+
+// Dataset is a set of artifacts and edges between them generated before it is written to a backend.
+type Dataset struct {
+	Artifacts []Artifact
+	Edges     []Edge
+}
+
+// Keys returns the keys of all artifacts and edges in the dataset.
+func (d Dataset) Keys() Graph {
+	return Graph{ArtifactKeys: artifactKeys(d.Artifacts), EdgeKeys: edgeKeys(d.Edges)}
+}
+
+func artifactKeys(artifacts []Artifact) []string {
+	keys := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		keys[i] = artifact.Key
+	}
+	return keys
+}
+
+// sortArtifacts sorts the artifacts by their creation time and then by their keys, as the databases list them.
+func sortArtifacts(artifacts []Artifact) {
+	sort.Slice(artifacts, func(i, j int) bool {
+		if !artifacts[i].CreateTime.Equal(artifacts[j].CreateTime) {
+			return artifacts[i].CreateTime.Before(artifacts[j].CreateTime)
+		}
+		return artifacts[i].Key < artifacts[j].Key
+	})
+}
+
+func edgeKeys(edges []Edge) []string {
+	keys := make([]string, len(edges))
+	for i, edge := range edges {
+		keys[i] = edge.Key
+	}
+	return keys
+}
+
+// Clock returns the creation time of the next generated artifact.
+type Clock func() time.Time
+
+// IDSource returns the key of the next generated artifact or edge.
+type IDSource func() string
+
+// GeneratorEpoch is the creation time of the first artifact generated by the default clock. The pairs are
+// dated from 2000 on their own, one day apart, the year queried by the scenarios never meets the other data.
+var GeneratorEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// storedEpoch returns the creation time of the artifact following n artifacts populated from GeneratorEpoch on,
+// so a resumed populate continues their order.
+func storedEpoch(n int) time.Time {
+	return GeneratorEpoch.Add(time.Duration(n) * time.Millisecond)
+}
+
+// runEpoch returns the start of the clock of a scenario run of the seed on n stored artifacts. The run dates
+// after them, moved by up to about a year by the seed, so the runs do not repeat the times of each other.
+func runEpoch(n int, seed int64) time.Time {
+	return storedEpoch(n).Add(time.Duration(uint64(seed)%(1<<35)) * time.Millisecond)
+}
+
+// StepClock returns the start time first and then every next time step later.
+func StepClock(start time.Time, step time.Duration) Clock {
+	next := start
+	return func() time.Time {
+		tm := next
+		next = next.Add(step)
+		return tm
+	}
+}
+
+// RandomIDs returns random (version 4) UUIDs read from the source, so a seeded source gives the same keys.
+func RandomIDs(source *rand.Rand) IDSource {
+	return func() string {
+		key, _ := uuid.NewRandomFromReader(source)
+		return key.String()
+	}
+}
+
+// Generator generates the artifacts, edges and updates written by the scenarios and the populate commands.
+// Generators of the same seed generate the same data, keys and timestamps, so every backend gets an identical
+// dataset and a suspicious result can be rerun. The clock, the keys and the payload can be replaced before the
+// first use.
+type Generator struct {
+	rand *rand.Rand
+
+	Clock Clock
+	IDs   IDSource
+
+	// Payload shapes the payload of the created artifacts, the updates do not change it.
+	Payload PayloadConfig
+}
+
+// NewGenerator creates a generator of the seed. Its keys are read from the seeded source and its clock starts
+// at GeneratorEpoch and ticks one millisecond per artifact.
+func NewGenerator(seed int64) *Generator {
+	source := rand.New(rand.NewSource(seed))
+	return &Generator{
+		rand:  source,
+		Clock: StepClock(GeneratorEpoch, time.Millisecond),
+		IDs:   RandomIDs(rand.New(rand.NewSource(source.Int63()))),
+	}
+}
+
+// DeriveSeed derives an independent seed of a named part of a run, e.g. the i-th run of a scenario.
+func DeriveSeed(seed int64, name string, i int) int64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s/%d", seed, name, i)
+	return int64(h.Sum64())
+}
+
+// intn returns a random number in [0, n).
+func (g *Generator) intn(n int) int {
+	return g.rand.Intn(n)
+}
+
+func (g *Generator) artifact(name string, i int, tm time.Time) Artifact {
+	return Artifact{
+		Key:         g.IDs(),
+		Name:        fmt.Sprintf("%s-%d", name, i),
+		Description: fmt.Sprintf("description-%d", i),
+		CreateTime:  tm,
+		Item:        1,
+		Payload:     g.payload(),
+	}
+}
+
+func (g *Generator) edge(from, to string, i int) Edge {
+	return Edge{
+		Key:  g.IDs(),
+		From: from,
+		To:   to,
+		Body: fmt.Sprintf("body-%d", i),
+	}
+}
+
+// Artifacts generates n artifacts.
+func (g *Generator) Artifacts(n int) []Artifact {
+	artifacts := make([]Artifact, n)
+	for i := range artifacts {
+		artifacts[i] = g.artifact("artifact", i, g.Clock())
+	}
+	return artifacts
+}
+
+// Updates generates new content of the artifacts with the given keys. The updates keep the creation time, the
+// order of the entries stays the same on all backends.
+func (g *Generator) Updates(keys []string) []Artifact {
+	artifacts := make([]Artifact, len(keys))
+	for i, key := range keys {
+		j := g.intn(1000)
+		artifacts[i] = Artifact{
+			Key:         key,
+			Name:        fmt.Sprintf("new-artifact-%d", j),
+			Description: fmt.Sprintf("new-description-%d", j),
+			Item:        1,
+		}
+	}
+	return artifacts
+}
+
+// Pairs generates n pairs. Pair is an artifact connected with another artifact: A1 --> A2. Every pair is
+// created one day after the previous one, starting at 2000-01-01.
+func (g *Generator) Pairs(n int) Dataset {
+
+	var dataset Dataset
+
+	tm := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < n; i++ {
+		from := g.artifact("artifact-from", i, tm)
+		to := g.artifact("artifact-to", i, tm)
+
+		dataset.Artifacts = append(dataset.Artifacts, from, to)
+		dataset.Edges = append(dataset.Edges, g.edge(from.Key, to.Key, i))
+		tm = tm.AddDate(0, 0, 1)
+	}
+
+	return dataset
+}
+
+// Chain generates a chain of n artifacts: A1 --> A2 --> ... --> An.
+func (g *Generator) Chain(n int) Dataset {
+
+	if n < 1 {
+		return Dataset{}
+	}
+
+	last := g.artifact("artifact", 0, g.Clock())
+	dataset := Dataset{Artifacts: []Artifact{last}}
+
+	for i := 0; i < n-1; i++ {
+		artifact := g.artifact("artifact", i+1, g.Clock())
+
+		dataset.Artifacts = append(dataset.Artifacts, artifact)
+		dataset.Edges = append(dataset.Edges, g.edge(last.Key, artifact.Key, i))
+		last = artifact
+	}
+
+	return dataset
+}
+
+// Neighbours generates one parent and n-1 direct neighbours of it.
+func (g *Generator) Neighbours(n int) Dataset {
+
+	if n < 1 {
+		return Dataset{}
+	}
+
+	parent := g.artifact("artifact", 0, g.Clock())
+	dataset := Dataset{Artifacts: []Artifact{parent}}
+
+	for i := 0; i < n-1; i++ {
+		artifact := g.artifact("artifact", i+1, g.Clock())
+
+		dataset.Artifacts = append(dataset.Artifacts, artifact)
+		dataset.Edges = append(dataset.Edges, g.edge(parent.Key, artifact.Key, i))
+	}
+
+	return dataset
+}
@@ -0,0 +1,92 @@
+package db_bench
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratorSeed(t *testing.T) {
+
+	generate := func(seed int64) []Dataset {
+		g := NewGenerator(seed)
+		return []Dataset{
+			{Artifacts: g.Artifacts(10)},
+			{Artifacts: g.Updates([]string{"a", "b"})},
+			g.Pairs(10),
+			g.Chain(10),
+			g.Neighbours(10),
+		}
+	}
+
+	assert.Equal(t, generate(1), generate(1))
+	assert.NotEqual(t, generate(1), generate(2))
+
+	artifacts := NewGenerator(1).Artifacts(2)
+	assert.Equal(t, GeneratorEpoch, artifacts[0].CreateTime)
+	assert.Equal(t, GeneratorEpoch.Add(time.Millisecond), artifacts[1].CreateTime)
+	assert.True(t, NewGenerator(1).Updates([]string{"a"})[0].CreateTime.IsZero())
+}
+
+func TestGeneratorSources(t *testing.T) {
+
+	var i int
+	g := NewGenerator(1)
+	g.IDs = func() string {
+		i++
+		return fmt.Sprintf("key-%d", i)
+	}
+	tm := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.Clock = StepClock(tm, time.Hour)
+
+	chain := g.Chain(3)
+	assert.Equal(t, []string{"key-1", "key-2", "key-4"}, artifactKeys(chain.Artifacts))
+	assert.Equal(t, []string{"key-3", "key-5"}, edgeKeys(chain.Edges))
+	assert.Equal(t, tm.Add(2*time.Hour), chain.Artifacts[2].CreateTime)
+}
+
+func TestDeriveSeed(t *testing.T) {
+	assert.Equal(t, DeriveSeed(1, "Create10", 0), DeriveSeed(1, "Create10", 0))
+	assert.NotEqual(t, DeriveSeed(1, "Create10", 0), DeriveSeed(1, "Create10", 1))
+	assert.NotEqual(t, DeriveSeed(1, "Create10", 0), DeriveSeed(1, "Create100", 0))
+	assert.NotEqual(t, DeriveSeed(1, "Create10", 0), DeriveSeed(2, "Create10", 0))
+}
+
+// TestBenchSeed runs the scenarios in different order on two backends, the same scenario run creates the same
+// data on both.
+func TestBenchSeed(t *testing.T) {
+
+	ctx := context.Background()
+
+	create, _ := FindScenario("Create10")
+	pairs, _ := FindScenario("CreateConnectedPairs10")
+
+	stored := func(scenarios ...Scenario) map[string]Artifact {
+		backend := NewMemoryBackend()
+		bench, err := NewBench(ctx, backend)
+		require.NoError(t, err)
+		bench.Seed = 7
+		for _, scenario := range scenarios {
+			_, err := bench.Run(ctx, scenario)
+			require.NoError(t, err)
+		}
+		return backend.(*memoryBackend).artifacts
+	}
+
+	assert.Equal(t, stored(create, pairs), stored(pairs))
+	assert.NotEqual(t, stored(create), stored(create, create))
+
+	// Every run starts its clock at its own time after the pre-populated artifacts.
+	times := make(map[time.Time]bool)
+	for _, artifact := range stored(create) {
+		times[artifact.CreateTime] = true
+	}
+	for _, artifact := range stored(create, create) {
+		assert.True(t, artifact.CreateTime.After(GeneratorEpoch))
+		assert.False(t, times[artifact.CreateTime])
+	}
+}
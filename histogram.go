@@ -0,0 +1,196 @@
+package db_bench
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// histogramSubBits sets the precision of the histogram, every power of two range is split into 64 buckets
+	// (2^(histogramSubBits-1)), so a recorded value is off by less than 1.6 %.
+	histogramSubBits  = 7
+	histogramSubCount = 1 << histogramSubBits
+	histogramSubHalf  = histogramSubCount / 2
+
+	// histogramUnit is the resolution of the histogram.
+	histogramUnit = time.Microsecond
+)
+
+// Histogram records durations into log-linear buckets in the manner of HdrHistogram: values up to 128 µs
+// are exact, larger ones keep a constant relative precision. It needs a fixed small memory no matter how many
+// values are recorded and histograms of concurrent recorders can be merged. It is not safe for concurrent use.
+type Histogram struct {
+	counts []int64
+	total  int64
+	min    time.Duration
+	max    time.Duration
+	sum    float64
+	sumSq  float64
+}
+
+// HistogramBucket is a non-empty bucket of a histogram given by its highest value.
+type HistogramBucket struct {
+	Value time.Duration `json:"value"`
+	Count int64         `json:"count"`
+}
+
+// NewHistogram creates an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Record adds the duration to the histogram.
+func (h *Histogram) Record(d time.Duration) {
+	h.RecordN(d, 1)
+}
+
+// RecordN adds the duration n times to the histogram.
+func (h *Histogram) RecordN(d time.Duration, n int64) {
+
+	if d < 0 {
+		d = 0
+	}
+
+	i := histogramIndex(uint64(d / histogramUnit))
+	for len(h.counts) <= i {
+		h.counts = append(h.counts, 0)
+	}
+	h.counts[i] += n
+
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total += n
+	h.sum += float64(d) * float64(n)
+	h.sumSq += float64(d) * float64(d) * float64(n)
+}
+
+// Merge adds all values recorded by the other histogram.
+func (h *Histogram) Merge(other *Histogram) {
+
+	if other == nil || other.total == 0 {
+		return
+	}
+
+	for len(h.counts) < len(other.counts) {
+		h.counts = append(h.counts, 0)
+	}
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+
+	if h.total == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.total += other.total
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Percentile returns the highest value equivalent to the p-th percentile of the recorded values, limited by
+// the minimum and maximum recorded value.
+func (h *Histogram) Percentile(p float64) time.Duration {
+
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			value := time.Duration(histogramHighest(i)) * histogramUnit
+			if value > h.max {
+				value = h.max
+			}
+			if value < h.min {
+				value = h.min
+			}
+			return value
+		}
+	}
+
+	return h.max
+}
+
+// Stats summarizes the recorded values. The minimum, maximum, mean and deviation are exact, the percentiles
+// have the precision of the buckets.
+func (h *Histogram) Stats() Stats {
+
+	if h.total == 0 {
+		return Stats{}
+	}
+
+	n := float64(h.total)
+	mean := h.sum / n
+
+	var stdDev float64
+	if h.total > 1 {
+		stdDev = math.Sqrt(math.Max(0, (h.sumSq-n*mean*mean)/(n-1)))
+	}
+
+	return Stats{
+		Count:  int(h.total),
+		Min:    h.min,
+		Mean:   time.Duration(mean),
+		Median: h.Percentile(50),
+		P90:    h.Percentile(90),
+		P99:    h.Percentile(99),
+		Max:    h.max,
+		StdDev: time.Duration(stdDev),
+	}
+}
+
+// Buckets returns the non-empty buckets ordered by their values.
+func (h *Histogram) Buckets() []HistogramBucket {
+	var buckets []HistogramBucket
+	for i, count := range h.counts {
+		if count > 0 {
+			buckets = append(buckets, HistogramBucket{Value: time.Duration(histogramHighest(i)) * histogramUnit, Count: count})
+		}
+	}
+	return buckets
+}
+
+// histogramIndex returns the bucket of the value (in histogram units).
+func histogramIndex(v uint64) int {
+
+	if v < histogramSubCount {
+		return int(v)
+	}
+
+	shift := bits.Len64(v) - histogramSubBits
+	top := v >> uint(shift)
+
+	return histogramSubCount + (shift-1)*histogramSubHalf + int(top-histogramSubHalf)
+}
+
+// histogramHighest returns the highest value (in histogram units) falling into the bucket.
+func histogramHighest(i int) uint64 {
+
+	if i < histogramSubCount {
+		return uint64(i)
+	}
+
+	shift := (i-histogramSubCount)/histogramSubHalf + 1
+	top := uint64((i-histogramSubCount)%histogramSubHalf + histogramSubHalf)
+
+	return (top+1)<<uint(shift) - 1
+}
@@ -0,0 +1,69 @@
+package db_bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+
+	h := NewHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	stats := h.Stats()
+	assert.Equal(t, 1000, stats.Count)
+	assert.Equal(t, time.Millisecond, stats.Min)
+	assert.Equal(t, time.Second, stats.Max)
+	assert.InDelta(t, float64(500500*time.Microsecond), float64(stats.Mean), float64(time.Microsecond))
+
+	// Percentiles are precise to the bucket width.
+	assert.InEpsilon(t, float64(500*time.Millisecond), float64(stats.Median), 0.016)
+	assert.InEpsilon(t, float64(900*time.Millisecond), float64(stats.P90), 0.016)
+	assert.InEpsilon(t, float64(990*time.Millisecond), float64(stats.P99), 0.016)
+	assert.GreaterOrEqual(t, stats.Median, 500*time.Millisecond)
+}
+
+func TestHistogramExactSmallValues(t *testing.T) {
+
+	h := NewHistogram()
+	h.Record(10 * time.Microsecond)
+	h.Record(20 * time.Microsecond)
+	h.Record(30 * time.Microsecond)
+
+	assert.Equal(t, 20*time.Microsecond, h.Percentile(50))
+	assert.Equal(t, 30*time.Microsecond, h.Percentile(100))
+	assert.Equal(t, []HistogramBucket{
+		{Value: 10 * time.Microsecond, Count: 1},
+		{Value: 20 * time.Microsecond, Count: 1},
+		{Value: 30 * time.Microsecond, Count: 1},
+	}, h.Buckets())
+}
+
+func TestHistogramMerge(t *testing.T) {
+
+	a, b := NewHistogram(), NewHistogram()
+	a.RecordN(time.Millisecond, 99)
+	b.Record(time.Second)
+	a.Merge(b)
+	a.Merge(NewHistogram())
+
+	assert.Equal(t, int64(100), a.Count())
+	assert.Equal(t, time.Millisecond, a.Stats().Min)
+	assert.Equal(t, time.Second, a.Stats().Max)
+	assert.InEpsilon(t, float64(time.Millisecond), float64(a.Percentile(99)), 0.016)
+	assert.Equal(t, time.Second, a.Percentile(100))
+}
+
+func TestHistogramIndex(t *testing.T) {
+	for _, v := range []uint64{0, 1, 127, 128, 129, 255, 256, 1000, 123456789} {
+		i := histogramIndex(v)
+		assert.GreaterOrEqual(t, histogramHighest(i), v)
+		if i > 0 {
+			assert.Less(t, histogramHighest(i-1), v)
+		}
+	}
+}
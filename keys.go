@@ -0,0 +1,237 @@
+package db_bench
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// KeyDistribution is the distribution the read and update scenarios draw the keys of their entries from.
+type KeyDistribution string
+
+const (
+	// KeysSequential touches every entry created by the parent scenario once, in the order of creation.
+	KeysSequential KeyDistribution = "sequential"
+
+	// KeysUniform draws every stored entry with the same probability.
+	KeysUniform KeyDistribution = "uniform"
+
+	// KeysZipfian draws the entries by the Zipfian distribution, a few hot entries get most of the
+	// operations. The hot entries are scattered over the whole dataset, not only the oldest ones.
+	KeysZipfian KeyDistribution = "zipfian"
+
+	// KeysLatest draws the entries by the Zipfian distribution of their age, the newest entries are the hot
+	// ones.
+	KeysLatest KeyDistribution = "latest"
+
+	// KeysHotspot draws the hot part of the entries (the oldest ones) by a fixed share of the operations, both
+	// uniformly within.
+	KeysHotspot KeyDistribution = "hotspot"
+)
+
+// KeyDistributions lists all key distributions.
+var KeyDistributions = []KeyDistribution{KeysSequential, KeysUniform, KeysZipfian, KeysLatest, KeysHotspot}
+
+func (d KeyDistribution) valid() bool {
+	for _, distribution := range KeyDistributions {
+		if d == distribution {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys draws n keys from the space ordered from the oldest entry to the newest one by the access pattern. The
+// keys repeat, the hot ones many times.
+func (g *Generator) Keys(space []string, n int, access AccessConfig) []string {
+
+	if len(space) == 0 {
+		return nil
+	}
+
+	keys := make([]string, n)
+	for i, position := range g.positions(len(space), n, access) {
+		keys[i] = space[position]
+	}
+
+	return keys
+}
+
+// positions draws n positions in a space of the size by the access pattern.
+func (g *Generator) positions(size, n int, access AccessConfig) []int {
+
+	next := g.positioner(access)
+
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = next(size)
+	}
+
+	return positions
+}
+
+// positioner returns the function drawing one position by the access pattern in a space of the given size. The
+// space may grow between the draws, the inserted entries are the newest ones.
+func (g *Generator) positioner(access AccessConfig) func(size int) int {
+
+	// The zeta sum of a growing space continues from the previous one, only a new space sums it up (cached).
+	var zipf zipfian
+	zipfianFor := func(size int) zipfian {
+		switch {
+		case zipf.n > 0 && size > zipf.n:
+			zipf = zipf.grow(size)
+		case zipf.n != size:
+			zipf = newZipfian(size, access.Zipfian)
+		}
+		return zipf
+	}
+
+	switch KeyDistribution(access.Distribution) {
+	case KeysZipfian:
+		return func(size int) int { return scatter(zipfianFor(size).next(g.rand.Float64()), size) }
+	case KeysLatest:
+		return func(size int) int { return size - 1 - zipfianFor(size).next(g.rand.Float64()) }
+	case KeysHotspot:
+		return func(size int) int {
+			hot := size * access.HotspotKeys / 100
+			if hot < 1 {
+				hot = 1
+			}
+			if g.intn(100) < access.HotspotOps || hot == size {
+				return g.intn(hot)
+			}
+			return hot + g.intn(size-hot)
+		}
+	case KeysSequential:
+		i := -1
+		return func(size int) int {
+			i++
+			return i % size
+		}
+	default:
+		return func(size int) int { return g.intn(size) }
+	}
+}
+
+// distinctKeys returns the keys without repetitions, in the order of their first occurrence. The bulk
+// operations touch every entry once.
+func distinctKeys(keys []string) []string {
+
+	seen := make(map[string]bool, len(keys))
+	distinct := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			distinct = append(distinct, key)
+		}
+	}
+
+	return distinct
+}
+
+// zipfian draws ranks from [0, n) by the Zipfian distribution of the constant theta in (0, 1), rank 0 being
+// the most frequent one. It is the algorithm of Gray et al., "Quickly Generating Billion-Record Synthetic
+// Databases", used by YCSB.
+type zipfian struct {
+	n     int
+	theta float64
+	alpha float64
+	zetan float64
+	eta   float64
+}
+
+func newZipfian(n int, theta float64) zipfian {
+	return zipfianOf(n, theta, zeta(n, theta))
+}
+
+func zipfianOf(n int, theta, zetan float64) zipfian {
+	return zipfian{
+		n:     n,
+		theta: theta,
+		alpha: 1 / (1 - theta),
+		zetan: zetan,
+		eta:   (1 - math.Pow(2/float64(n), 1-theta)) / (1 - zeta(2, theta)/zetan),
+	}
+}
+
+// grow returns the distribution of the larger space n, its zeta sum continues from the current one.
+func (z zipfian) grow(n int) zipfian {
+
+	zetan := z.zetan
+	for i := z.n + 1; i <= n; i++ {
+		zetan += 1 / math.Pow(float64(i), z.theta)
+	}
+
+	return zipfianOf(n, z.theta, zetan)
+}
+
+// zetaKey identifies a cached zeta sum.
+type zetaKey struct {
+	n     int
+	theta float64
+}
+
+// zetaSums caches the zeta sums, the key spaces of the scenarios reach millions of entries and every scenario
+// run draws its keys again.
+var zetaSums = struct {
+	sync.Mutex
+	sums map[zetaKey]float64
+}{sums: make(map[zetaKey]float64)}
+
+// zeta returns the sum of 1/i^theta for i from 1 to n. The sum continues from the largest cached one of the
+// theta below n as YCSB does when the key space grows.
+func zeta(n int, theta float64) float64 {
+
+	zetaSums.Lock()
+	defer zetaSums.Unlock()
+
+	if sum, ok := zetaSums.sums[zetaKey{n, theta}]; ok {
+		return sum
+	}
+
+	var from int
+	var sum float64
+	for key, cached := range zetaSums.sums {
+		if key.theta == theta && key.n < n && key.n > from {
+			from, sum = key.n, cached
+		}
+	}
+
+	for i := from + 1; i <= n; i++ {
+		sum += 1 / math.Pow(float64(i), theta)
+	}
+
+	zetaSums.sums[zetaKey{n, theta}] = sum
+
+	return sum
+}
+
+// next returns the rank of the uniform random number u from [0, 1).
+func (z zipfian) next(u float64) int {
+
+	uz := u * z.zetan
+
+	if uz < 1 {
+		return 0
+	}
+
+	if uz < 1+math.Pow(0.5, z.theta) {
+		return 1
+	}
+
+	rank := int(float64(z.n) * math.Pow(z.eta*u-z.eta+1, z.alpha))
+	if rank >= z.n {
+		rank = z.n - 1
+	}
+
+	return rank
+}
+
+// scatter maps the rank to a position in [0, n) by its hash, so the hot ranks do not neighbour.
+func scatter(rank, n int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.Itoa(rank)))
+	return int(h.Sum64() % uint64(n))
+}
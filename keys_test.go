@@ -0,0 +1,141 @@
+package db_bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drawKeys draws the keys of a space of n keys and returns how many times each position was drawn.
+func drawKeys(access AccessConfig, n, draws int) []int {
+
+	space := make([]string, n)
+	position := make(map[string]int, n)
+	for i := range space {
+		space[i] = fmt.Sprintf("key-%d", i)
+		position[space[i]] = i
+	}
+
+	counts := make([]int, n)
+	for _, key := range NewGenerator(1).Keys(space, draws, access) {
+		counts[position[key]]++
+	}
+
+	return counts
+}
+
+func maxCount(counts []int) (int, int) {
+	var max, at int
+	for i, count := range counts {
+		if count > max {
+			max, at = count, i
+		}
+	}
+	return max, at
+}
+
+func TestKeysDistribution(t *testing.T) {
+
+	access := DefaultConfig().Access
+
+	access.Distribution = string(KeysSequential)
+	assert.Equal(t, []string{"a", "b", "c", "a"}, NewGenerator(1).Keys([]string{"a", "b", "c"}, 4, access))
+
+	access.Distribution = string(KeysUniform)
+	max, _ := maxCount(drawKeys(access, 1000, 100000))
+	assert.Less(t, max, 200)
+
+	// The hottest key of the Zipfian distribution gets about 1/zeta(1000) of the operations.
+	access.Distribution = string(KeysZipfian)
+	max, at := maxCount(drawKeys(access, 1000, 100000))
+	assert.Greater(t, max, 10000)
+	assert.NotZero(t, at, "hot keys are scattered")
+
+	access.Distribution = string(KeysLatest)
+	max, at = maxCount(drawKeys(access, 1000, 100000))
+	assert.Greater(t, max, 10000)
+	assert.Equal(t, 999, at)
+
+	access.Distribution = string(KeysHotspot)
+	counts := drawKeys(access, 1000, 100000)
+	var hot int
+	for _, count := range counts[:200] {
+		hot += count
+	}
+	assert.InDelta(t, 80000, hot, 1000)
+}
+
+func TestZipfianRanks(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 1000} {
+		zipf := newZipfian(n, 0.99)
+		for _, u := range []float64{0, 0.1, 0.5, 0.9, 0.999999} {
+			rank := zipf.next(u)
+			assert.True(t, rank >= 0 && rank < n, "rank %d of %d", rank, n)
+		}
+	}
+}
+
+func TestZeta(t *testing.T) {
+
+	var sum float64
+	for i := 1; i <= 1000; i++ {
+		sum += 1 / math.Pow(float64(i), 0.5)
+	}
+
+	// The sum continues from the cached one of the smaller space.
+	zeta(300, 0.5)
+	assert.Equal(t, sum, zeta(1000, 0.5))
+	assert.Equal(t, sum, zeta(1000, 0.5))
+	assert.NotEqual(t, sum, zeta(1000, 0.6))
+
+	assert.Equal(t, newZipfian(1000, 0.5), newZipfian(300, 0.5).grow(1000))
+}
+
+func TestDistinctKeys(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, distinctKeys([]string{"b", "a", "b", "c", "a"}))
+}
+
+// TestBenchAccessKeys runs the read and update scenarios on a pre-populated backend, the drawn keys include the
+// pre-populated artifacts and the oracle is not asked about them.
+func TestBenchAccessKeys(t *testing.T) {
+
+	ctx := context.Background()
+
+	backend := NewMemoryBackend()
+	static := NewGenerator(2).Artifacts(1000)
+	require.NoError(t, backend.BulkCreate(ctx, static))
+
+	bench, err := NewBench(ctx, backend)
+	require.NoError(t, err)
+	bench.Oracle = NewMemoryBackend()
+	bench.Access = DefaultConfig().Access
+	bench.Access.Distribution = string(KeysUniform)
+
+	for _, name := range []string{"BulkCreate10000", "Read10000", "BulkRead10000", "Update10000", "BulkUpdate10000", "QueryRead10000"} {
+		scenario, _ := FindScenario(name)
+		_, err := bench.Run(ctx, scenario)
+		require.NoError(t, err, name)
+	}
+
+	keys, includesStatic, err := bench.accessKeys(ctx, 100000, bench.Access)
+	require.NoError(t, err)
+	assert.True(t, includesStatic)
+	assert.Len(t, bench.staticKeys, 1000)
+
+	drawn := map[string]bool{}
+	for _, key := range keys {
+		drawn[key] = true
+	}
+	assert.True(t, drawn[static[0].Key])
+	assert.True(t, drawn[bench.data.ArtifactKeys[0]])
+
+	require.NoError(t, bench.Close(ctx))
+
+	artifacts, _, err := backend.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, artifacts)
+}
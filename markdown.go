@@ -0,0 +1,205 @@
+package db_bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// backendTitles are the names of the backends shown in the table header.
+var backendTitles = map[string]string{
+	"arangodb": "ArangoDB",
+	"postgres": "PostgreSQL",
+	"neo4j":    "Neo4j",
+	"sqlite":   "SQLite",
+	"bbolt":    "bbolt",
+	"memory":   "Memory",
+
+	"arangodb-inlined": "ArangoDB (inlined)",
+	"postgres-inlined": "PostgreSQL (inlined)",
+	"postgres-values":  "PostgreSQL (VALUES)",
+	"postgres-unnest":  "PostgreSQL (unnest)",
+	"pgx":              "PostgreSQL (pgx)",
+}
+
+// scenarioTitles link the first scenario of a family to the README section describing its data.
+var scenarioTitles = map[string]string{
+	"CreateConnectedPairs10":  "Create 10 connected [pairs](#pair)",
+	"QueryNeighbourInChain10": "Query 10th artifact in [chain](#chain)",
+	"CreateNeighbours100":     "Create 100 direct [neighbours](#direct-neighbours)",
+}
+
+// WriteMarkdown renders the results as Markdown tables, one scenario per row and one backend per column. The
+// results are grouped into one table per number of pre-populated artifacts. A cell shows the median of the
+// measured phase, `skipped` if the scenario could not run, `N/A` if the backend has no equivalent of the
+// scenario, `failed` if it failed and `-` if it was not run at all.
+func WriteMarkdown(w io.Writer, results []Result) error {
+
+	var counts []int
+	groups := make(map[int][]Result)
+	for _, result := range results {
+		if _, ok := groups[result.StaticArtifactCount]; !ok {
+			counts = append(counts, result.StaticArtifactCount)
+		}
+		groups[result.StaticArtifactCount] = append(groups[result.StaticArtifactCount], result)
+	}
+	sort.Ints(counts)
+
+	for i, count := range counts {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		if count == 0 {
+			fmt.Fprint(w, "Empty database:\n\n")
+		} else {
+			fmt.Fprintf(w, "Pre-populated database of **%d** entries:\n\n", count)
+		}
+
+		if err := writeMarkdownTable(w, groups[count]); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "\n* Tests indented by ↪ depend on previous not-indented test.\n"+
+		"* `skipped` tests could not run as the test they depend on did not pass, `N/A` tests have no equivalent in "+
+		"the database API.\n")
+	return err
+}
+
+func writeMarkdownTable(w io.Writer, results []Result) error {
+
+	var backends []string
+	var rows []Result
+	cells := make(map[string]map[int]string)
+
+	for _, result := range results {
+		if _, ok := cells[result.Backend]; !ok {
+			backends = append(backends, result.Backend)
+			cells[result.Backend] = make(map[int]string)
+		}
+		if !hasRow(rows, result.Num) {
+			rows = append(rows, result)
+		}
+		cells[result.Backend][result.Num] = markdownCell(result)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Num < rows[j].Num })
+
+	table := [][]string{{"Num", "Test"}}
+	for _, backend := range backends {
+		title, ok := backendTitles[backend]
+		if !ok {
+			title = backend
+		}
+		table[0] = append(table[0], title)
+	}
+
+	for _, row := range rows {
+		title, ok := scenarioTitles[row.Scenario]
+		if !ok {
+			title = row.Title
+		}
+		if row.Depends != "" {
+			title = "↪ " + title
+		}
+
+		line := []string{strconv.Itoa(row.Num), title}
+		for _, backend := range backends {
+			cell, ok := cells[backend][row.Num]
+			if !ok {
+				cell = "-"
+			}
+			line = append(line, cell)
+		}
+		table = append(table, line)
+	}
+
+	widths := make([]int, len(table[0]))
+	for _, line := range table {
+		for i, cell := range line {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	for i, line := range table {
+		if err := writeMarkdownLine(w, line, widths); err != nil {
+			return err
+		}
+		if i == 0 {
+			separator := make([]string, len(widths))
+			separator[0] = strings.Repeat("-", widths[0]-1) + ":"
+			for j := 1; j < len(widths); j++ {
+				separator[j] = strings.Repeat("-", widths[j])
+			}
+			if err := writeMarkdownLine(w, separator, widths); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeMarkdownLine(w io.Writer, line []string, widths []int) error {
+
+	var b strings.Builder
+	b.WriteString("|")
+	for i, cell := range line {
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if i == 0 {
+			// The number column is aligned to the right.
+			b.WriteString(" " + padding + cell + " |")
+		} else {
+			b.WriteString(" " + cell + padding + " |")
+		}
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func hasRow(rows []Result, num int) bool {
+	for _, row := range rows {
+		if row.Num == num {
+			return true
+		}
+	}
+	return false
+}
+
+func markdownCell(result Result) string {
+	switch result.Status {
+	case StatusOK:
+		return formatMarkdownDuration(result.Stats.Median)
+	case StatusSkipped:
+		return "skipped"
+	case StatusNotImplemented:
+		return "N/A"
+	default:
+		return "failed"
+	}
+}
+
+// formatMarkdownDuration formats the duration the way the README does: whole milliseconds below one second,
+// seconds with one decimal place otherwise.
+func formatMarkdownDuration(d time.Duration) string {
+
+	if d < time.Millisecond {
+		return "< 1 ms"
+	}
+
+	if rounded := d.Round(time.Millisecond); rounded < time.Second {
+		return fmt.Sprintf("%d ms", rounded.Milliseconds())
+	}
+
+	seconds := math.Round(d.Seconds()*10) / 10
+	return strconv.FormatFloat(seconds, 'f', -1, 64) + " s"
+}
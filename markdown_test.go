@@ -0,0 +1,63 @@
+package db_bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+
+	create, _ := FindScenario("BulkCreate10000")
+	read, _ := FindScenario("Read10000")
+	update, _ := FindScenario("Update10000")
+
+	results := []Result{
+		NewResult("arangodb", create, []Timing{{Measured: 401 * time.Millisecond}}, nil),
+		NewResult("arangodb", read, []Timing{{Measured: 10 * time.Second}}, nil),
+		NewResult("postgres", create, []Timing{{Measured: 4200 * time.Millisecond}}, nil),
+		NewResult("postgres", read, nil, errors.Wrap(ErrNotImplemented, "read")),
+		NewResult("postgres", update, nil, errors.Wrap(ErrSkipped, "parent failed")),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, results))
+
+	lines := strings.Split(buf.String(), "\n")
+	require.Greater(t, len(lines), 7)
+
+	assert.Equal(t, "Empty database:", lines[0])
+	assert.Equal(t, "| Num | Test                               | ArangoDB | PostgreSQL |", lines[2])
+	assert.Equal(t, "| --: | ---------------------------------- | -------- | ---------- |", lines[3])
+	assert.Equal(t, "|   5 | Create 10000 entries (bulk)        | 401 ms   | 4.2 s      |", lines[4])
+	assert.Equal(t, "|   6 | ↪ Read 10000 entries (not a query) | 10 s     | N/A        |", lines[5])
+	assert.Equal(t, "|   8 | ↪ Update 10000 entries             | -        | skipped    |", lines[6])
+}
+
+func TestWriteMarkdownGroups(t *testing.T) {
+
+	create, _ := FindScenario("Create10")
+
+	empty := NewResult("arangodb", create, []Timing{{Measured: time.Millisecond}}, nil)
+	populated := empty
+	populated.StaticArtifactCount = 1000000
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, []Result{populated, empty}))
+
+	out := buf.String()
+	assert.Less(t, strings.Index(out, "Empty database:"), strings.Index(out, "Pre-populated database of **1000000** entries:"))
+}
+
+func TestFormatMarkdownDuration(t *testing.T) {
+	assert.Equal(t, "< 1 ms", formatMarkdownDuration(500*time.Microsecond))
+	assert.Equal(t, "15 ms", formatMarkdownDuration(15400*time.Microsecond))
+	assert.Equal(t, "1 s", formatMarkdownDuration(999600*time.Microsecond))
+	assert.Equal(t, "1.4 s", formatMarkdownDuration(1414*time.Millisecond))
+	assert.Equal(t, "110.7 s", formatMarkdownDuration(110700*time.Millisecond))
+}
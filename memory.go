@@ -0,0 +1,314 @@
+package db_bench
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOracleMismatch is returned when a backend answers differently than the oracle.
+var ErrOracleMismatch = errors.New("differs from oracle")
+
+// memoryBackend is a plain Go graph store. It implements every scenario in the most obvious way, so it serves
+// as a baseline of the pure algorithmic cost and as the oracle other backends are checked against. It is safe
+// for concurrent use.
+type memoryBackend struct {
+	mu        sync.RWMutex
+	artifacts map[string]Artifact
+	edges     map[string]Edge
+
+	// Keys of the edges going out of an artifact, in the order of their creation.
+	outgoing map[string][]string
+}
+
+// NewMemoryBackend creates an empty in-memory backend.
+func NewMemoryBackend() Backend {
+	return &memoryBackend{
+		artifacts: make(map[string]Artifact),
+		edges:     make(map[string]Edge),
+		outgoing:  make(map[string][]string),
+	}
+}
+
+func (b *memoryBackend) Name() string {
+	return "memory"
+}
+
+func (b *memoryBackend) Count(_ context.Context) (int, int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.artifacts), len(b.edges), nil
+}
+
+func (b *memoryBackend) Keys(_ context.Context) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	artifacts := make([]Artifact, 0, len(b.artifacts))
+	for _, artifact := range b.artifacts {
+		artifacts = append(artifacts, artifact)
+	}
+	sortArtifacts(artifacts)
+
+	return artifactKeys(artifacts), nil
+}
+
+func (b *memoryBackend) Create(_ context.Context, artifacts []Artifact) error {
+	for _, artifact := range artifacts {
+		if err := b.insert(Dataset{Artifacts: []Artifact{artifact}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *memoryBackend) BulkCreate(_ context.Context, artifacts []Artifact) error {
+	return b.insert(Dataset{Artifacts: artifacts})
+}
+
+// insert stores the dataset, nothing is stored if any of the keys exists or any edge connects an unknown
+// artifact.
+func (b *memoryBackend) insert(dataset Dataset) error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	created := make(map[string]bool, len(dataset.Artifacts))
+	for _, artifact := range dataset.Artifacts {
+		if _, ok := b.artifacts[artifact.Key]; ok || created[artifact.Key] {
+			return errors.Errorf("artifact %s already exists", artifact.Key)
+		}
+		created[artifact.Key] = true
+	}
+
+	for _, edge := range dataset.Edges {
+		if _, ok := b.edges[edge.Key]; ok {
+			return errors.Errorf("edge %s already exists", edge.Key)
+		}
+		for _, key := range []string{edge.From, edge.To} {
+			if _, ok := b.artifacts[key]; !ok && !created[key] {
+				return errors.Errorf("edge %s connects unknown artifact %s", edge.Key, key)
+			}
+		}
+	}
+
+	for _, artifact := range dataset.Artifacts {
+		b.artifacts[artifact.Key] = artifact
+	}
+
+	for _, edge := range dataset.Edges {
+		b.edges[edge.Key] = edge
+		b.outgoing[edge.From] = append(b.outgoing[edge.From], edge.Key)
+	}
+
+	return nil
+}
+
+func (b *memoryBackend) Read(_ context.Context, key string) error {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if _, ok := b.artifacts[key]; !ok {
+		return errors.Errorf("artifact %s not found", key)
+	}
+
+	return nil
+}
+
+func (b *memoryBackend) BulkRead(_ context.Context, keys []string) (int, error) {
+	return b.countExisting(keys), nil
+}
+
+func (b *memoryBackend) Query(_ context.Context, keys []string) (int, error) {
+	return b.countExisting(keys), nil
+}
+
+func (b *memoryBackend) countExisting(keys []string) int {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var count int
+	for _, key := range keys {
+		if _, ok := b.artifacts[key]; ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (b *memoryBackend) Update(ctx context.Context, artifact Artifact) error {
+	_, err := b.BulkUpdate(ctx, []Artifact{artifact})
+	return err
+}
+
+// BulkUpdate changes the name and description of the existing artifacts, like the other backends do.
+func (b *memoryBackend) BulkUpdate(_ context.Context, artifacts []Artifact) (int, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var count int
+	for _, artifact := range artifacts {
+		stored, ok := b.artifacts[artifact.Key]
+		if !ok {
+			continue
+		}
+		stored.Name = artifact.Name
+		stored.Description = artifact.Description
+		b.artifacts[artifact.Key] = stored
+		count++
+	}
+
+	return count, nil
+}
+
+func (b *memoryBackend) CreatePairs(_ context.Context, dataset Dataset) error {
+	return b.insert(dataset)
+}
+
+func (b *memoryBackend) QueryPairs(_ context.Context) (int, error) {
+	return b.countPairs(func(Artifact) bool { return true }), nil
+}
+
+func (b *memoryBackend) QueryPairsInYear(_ context.Context, year int) (int, error) {
+	return b.countPairs(func(to Artifact) bool { return to.CreateTime.UTC().Year() == year }), nil
+}
+
+// countPairs counts the edges whose target artifact matches.
+func (b *memoryBackend) countPairs(match func(to Artifact) bool) int {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var count int
+	for _, edge := range b.edges {
+		if match(b.artifacts[edge.To]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (b *memoryBackend) CreateChain(_ context.Context, dataset Dataset) error {
+	return b.insert(dataset)
+}
+
+// QueryNeighbourN follows the first outgoing edge n times.
+func (b *memoryBackend) QueryNeighbourN(_ context.Context, key string, n int) (Artifact, error) {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	path, err := b.follow(key, n)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return path[len(path)-1], nil
+}
+
+func (b *memoryBackend) SumNeighbourItems(_ context.Context, key string, n int) (int, error) {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	path, err := b.follow(key, n)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum int
+	for _, artifact := range path {
+		sum += artifact.Item
+	}
+
+	return sum, nil
+}
+
+// follow returns the artifact and its n following neighbours in a chain.
+func (b *memoryBackend) follow(key string, n int) ([]Artifact, error) {
+
+	artifact, ok := b.artifacts[key]
+	if !ok {
+		return nil, errors.Errorf("artifact %s not found", key)
+	}
+
+	path := []Artifact{artifact}
+	for i := 0; i < n; i++ {
+		outgoing := b.outgoing[artifact.Key]
+		if len(outgoing) == 0 {
+			return nil, errors.Errorf("chain ends after %d neighbours", i)
+		}
+		artifact = b.artifacts[b.edges[outgoing[0]].To]
+		path = append(path, artifact)
+	}
+
+	return path, nil
+}
+
+func (b *memoryBackend) CreateNeighbours(_ context.Context, dataset Dataset) error {
+	return b.insert(dataset)
+}
+
+func (b *memoryBackend) QuerySortedNeighbours(_ context.Context, key string) (int, error) {
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var names []string
+	for _, edgeKey := range b.outgoing[key] {
+		names = append(names, b.artifacts[b.edges[edgeKey].To].Name)
+	}
+	sort.Strings(names)
+
+	return len(names), nil
+}
+
+func (b *memoryBackend) Cleanup(_ context.Context, graph Graph) error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	removed := make(map[string]bool, len(graph.EdgeKeys))
+	for _, key := range graph.EdgeKeys {
+		if edge, ok := b.edges[key]; ok {
+			removed[key] = true
+			removed[edge.From] = true
+			delete(b.edges, key)
+		}
+	}
+
+	// Both edge keys and the artifacts they go from are marked, the keys are unique UUIDs.
+	for from, outgoing := range b.outgoing {
+		if !removed[from] {
+			continue
+		}
+		kept := outgoing[:0]
+		for _, key := range outgoing {
+			if !removed[key] {
+				kept = append(kept, key)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.outgoing, from)
+		} else {
+			b.outgoing[from] = kept
+		}
+	}
+
+	for _, key := range graph.ArtifactKeys {
+		delete(b.artifacts, key)
+	}
+
+	return nil
+}
+
+func (b *memoryBackend) Close() error {
+	return nil
+}
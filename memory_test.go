@@ -0,0 +1,62 @@
+package db_bench
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySuite(t *testing.T) {
+	runScenarios(t, testConfig(t), NewMemoryBackend(), nil)
+}
+
+func TestMemoryQueries(t *testing.T) {
+	runQueries(t, NewMemoryBackend())
+}
+
+// wrongBackend answers one query differently than the memory backend.
+type wrongBackend struct {
+	Backend
+}
+
+func (b wrongBackend) BulkRead(ctx context.Context, keys []string) (int, error) {
+	count, err := b.Backend.BulkRead(ctx, keys)
+	return count - 1, err
+}
+
+func TestOracleMismatch(t *testing.T) {
+
+	ctx := context.Background()
+
+	bench, err := NewBench(ctx, wrongBackend{NewMemoryBackend()})
+	require.NoError(t, err)
+	bench.Oracle = NewMemoryBackend()
+
+	create, ok := FindScenario("BulkCreate10000")
+	require.True(t, ok)
+	_, err = bench.Run(ctx, create)
+	require.NoError(t, err)
+
+	count, _, err := bench.Oracle.Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 10000, count)
+
+	read, ok := FindScenario("BulkRead10000")
+	require.True(t, ok)
+
+	// Only the oracle is left to find out.
+	read.Prepare = func(ctx context.Context, b *Bench, params Params) (Execution, error) {
+		execution, err := bulkReadScenario(ctx, b, params)
+		execution.Verify = nil
+		return execution, err
+	}
+
+	_, err = bench.Run(ctx, read)
+	require.ErrorIs(t, err, ErrOracleMismatch)
+
+	require.NoError(t, bench.Close(ctx))
+	count, _, err = bench.Oracle.Count(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -1,19 +1,26 @@
 package db_bench
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/pkg/errors"
 )
 
 type neo4jEntity struct {
+	Key         string    `json:"key"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	CreateTime  time.Time `json:"create_time"`
 	Item        int       `json:"item"`
+
+	// Payload is added by toStruct as flat properties under the `payload.` prefix, the nested objects under
+	// dotted paths (`payload.nested.value`). Neo4j cannot store a map as a property value, the flat properties
+	// keep the nested payload readable and rebuildable into the same object.
+	Payload map[string]interface{} `json:"-"`
 }
 
 type neo4jRelation struct {
@@ -24,6 +31,8 @@ func (e neo4jEntity) toStruct() map[string]interface{} {
 	res := make(map[string]interface{})
 	data, _ := json.Marshal(e)
 	json.Unmarshal(data, &res)
+	// The payload keeps its types, byte slices are stored as byte arrays.
+	flattenPayload("payload.", e.Payload, res)
 	return res
 }
 
@@ -34,50 +43,49 @@ func (e neo4jRelation) toStruct() map[string]interface{} {
 	return res
 }
 
-func getName(id int) string {
-	return fmt.Sprintf("entity-%d", id)
+func newNeo4jEntity(artifact Artifact) neo4jEntity {
+	return neo4jEntity{
+		Key:         artifact.Key,
+		Name:        artifact.Name,
+		Description: artifact.Description,
+		CreateTime:  artifact.CreateTime,
+		Item:        artifact.Item,
+		Payload:     artifact.Payload,
+	}
 }
 
-func getDescription(id int) string {
-	return fmt.Sprintf("description-%d", id)
+func CreateNeo4jIndexes(db neo4j.Session) error {
+	_, err := db.Run("CREATE INDEX entity_key IF NOT EXISTS FOR (e:Entity) ON (e.key)", nil)
+	if err != nil {
+		return errors.Wrap(err, "failed creating index")
+	}
+
+	return nil
 }
 
-func createEntities(db neo4j.Session, count int) (created int, err error) {
-	_, err = db.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		for i := 0; i < count; i++ {
-			entity := neo4jEntity{
-				Name:        getName(i),
-				Description: getDescription(i),
-				CreateTime:  time.Now(),
-			}
-			entry := map[string]interface{}{"entity": entity.toStruct()}
-			_, err = tx.Run("CREATE (:Entity $entity)", entry)
+func createEntities(db neo4j.Session, artifacts []Artifact) error {
+	_, err := db.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		for _, artifact := range artifacts {
+			entry := map[string]interface{}{"entity": newNeo4jEntity(artifact).toStruct()}
+			_, err := tx.Run("CREATE (:Entity $entity)", entry)
 			if err != nil {
 				return nil, err
 			}
-
-			created++
 		}
 
 		return nil, nil
 	})
 
-	return
+	return err
 }
 
-func bulkCreateEntities(db neo4j.Session, count int) (created int, err error) {
-	var entities []neo4jEntity = make([]neo4jEntity, count)
-	for i := range entities {
-		entities[i] = neo4jEntity{
-			Name:        getName(i),
-			Description: getDescription(i),
-			CreateTime:  time.Now(),
-		}
+func bulkCreateEntities(db neo4j.Session, artifacts []Artifact) error {
+	var entities []interface{} = make([]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
+		entities[i] = newNeo4jEntity(artifact).toStruct()
 	}
 
 	var entry map[string]interface{} = map[string]interface{}{"batch": entities}
-	data, _ := json.Marshal(entry)
-	json.Unmarshal(data, &entry)
 	res, err := db.Run(
 		`WITH $batch as batch UNWIND batch as props
 		CREATE (n:Entity)
@@ -85,40 +93,58 @@ func bulkCreateEntities(db neo4j.Session, count int) (created int, err error) {
 		entry,
 	)
 	if err != nil {
-		return
+		return err
 	}
 
-	summary, err := res.Consume()
+	_, err = res.Consume()
+	return err
+}
+
+func readMultipleEntities(db neo4j.Session, keys []string) (int, error) {
+	cursor, err := db.Run(
+		`WITH $keys as keys
+		MATCH (e:Entity)
+		WHERE e.key IN keys
+		RETURN properties(e)`,
+		map[string]interface{}{"keys": keys},
+	)
 	if err != nil {
-		return
+		return 0, err
 	}
 
-	created = summary.Counters().NodesCreated()
-	return
+	retrieved := readAllFromCursor(cursor)
+
+	return retrieved, cursor.Err()
 }
 
-func readMultipleEntities(db neo4j.Session, count int) (retrieved int, err error) {
-	var nameList []string = make([]string, count)
-	for i := range nameList {
-		nameList[i] = fmt.Sprintf("new-name-%d", i)
+func readOneEntity(db neo4j.Session, key string) error {
+	_, err := neo4j.Single(db.Run(
+		`MATCH (e:Entity {key: $key})
+		RETURN properties(e)`,
+		map[string]interface{}{"key": key},
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed reading entity")
 	}
 
-	var entry map[string]interface{} = map[string]interface{}{"names": nameList}
-	data, _ := json.Marshal(entry)
-	json.Unmarshal(data, &entry)
+	return nil
+}
+
+// readBulkEntities looks every key up by the index, unlike readMultipleEntities filtering by a list.
+func readBulkEntities(db neo4j.Session, keys []string) (int, error) {
 	cursor, err := db.Run(
-		`WITH $names as names
-		MATCH (e:Entity)
-		WHERE e.name IN names
+		`UNWIND $keys AS key
+		MATCH (e:Entity {key: key})
 		RETURN properties(e)`,
-		entry,
+		map[string]interface{}{"keys": keys},
 	)
 	if err != nil {
-		return
+		return 0, err
 	}
 
-	retrieved = readAllFromCursor(cursor)
-	return
+	retrieved := readAllFromCursor(cursor)
+
+	return retrieved, cursor.Err()
 }
 
 func readAllFromCursor(c neo4j.Result) int {
@@ -132,29 +158,33 @@ func readAllFromCursor(c neo4j.Result) int {
 	return retrieved
 }
 
-func updateOneEntity(db neo4j.Session, id int) error {
-	key := getName(id)
-	i := rand.Intn(1000)
+func updateOneEntity(db neo4j.Session, artifact Artifact) error {
 	params := map[string]interface{}{
-		"key":         key,
-		"description": fmt.Sprintf("new-description-%d", i),
+		"key":         artifact.Key,
+		"name":        artifact.Name,
+		"description": artifact.Description,
 	}
-	_, err := db.Run(`
-		MATCH (e:Entity {name: $key})
+	res, err := db.Run(`
+		MATCH (e:Entity {key: $key})
+		SET e.name = $name
 		SET e.description = $description`,
 		params,
 	)
+	if err != nil {
+		return err
+	}
+
+	_, err = res.Consume()
 	return err
 }
 
-func bulkUpdateEntities(db neo4j.Session, count int) (updated int, err error) {
-	var updateList []map[string]interface{} = make([]map[string]interface{}, count)
-	for i := range updateList {
-		key := getName(i)
+func bulkUpdateEntities(db neo4j.Session, artifacts []Artifact) (updated int, err error) {
+	var updateList []map[string]interface{} = make([]map[string]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
 		updateList[i] = map[string]interface{}{
-			"key":         key,
-			"name":        fmt.Sprintf("new-name-%d", i),
-			"description": fmt.Sprintf("new-description-%d", i),
+			"key":         artifact.Key,
+			"name":        artifact.Name,
+			"description": artifact.Description,
 		}
 	}
 
@@ -162,8 +192,8 @@ func bulkUpdateEntities(db neo4j.Session, count int) (updated int, err error) {
 	ret, err := db.Run(`
 		WITH $params AS params
 		UNWIND params AS p
-		MATCH (e:Entity {name: p.key})
-		USING INDEX e:Entity(name)
+		MATCH (e:Entity {key: p.key})
+		USING INDEX e:Entity(key)
 		SET e.name = p.name
 		SET e.description = p.description`,
 		params,
@@ -173,27 +203,21 @@ func bulkUpdateEntities(db neo4j.Session, count int) (updated int, err error) {
 	}
 
 	res, err := ret.Consume()
+	if err != nil {
+		return
+	}
+
 	updated = res.Counters().PropertiesSet() / 2
 	return
 }
 
-func createConnectedPair(tx neo4j.Transaction, first int, second int, created time.Time) error {
-	entity1 := neo4jEntity{
-		Name:        getName(first),
-		Description: getDescription(first),
-		CreateTime:  created,
-	}
-	entity2 := neo4jEntity{
-		Name:        getName(second),
-		Description: getDescription(second),
-		CreateTime:  created,
-	}
+func createConnectedPair(tx neo4j.Transaction, from Artifact, to Artifact, edge Edge) error {
 	relation := neo4jRelation{
-		Body: fmt.Sprintf("Connection: %d->%d", first, second),
+		Body: edge.Body,
 	}
 	entry := map[string]interface{}{
-		"entity1":  entity1.toStruct(),
-		"entity2":  entity2.toStruct(),
+		"entity1":  newNeo4jEntity(from).toStruct(),
+		"entity2":  newNeo4jEntity(to).toStruct(),
 		"relation": relation.toStruct(),
 	}
 
@@ -207,21 +231,344 @@ func createConnectedPair(tx neo4j.Transaction, first int, second int, created ti
 	return err
 }
 
-func createConnectedPairs(db neo4j.Session, count int) (created int, err error) {
-	_, err = db.WriteTransaction(func(tx neo4j.Transaction) (res interface{}, err error) {
-		startDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
-		for i := 0; i < count*2; i = i + 2 {
-			err = createConnectedPair(tx, i, i+1, startDate)
+// createConnectedPairs creates the pairs, every edge of the dataset has to connect two artifacts stored next to
+// each other, as generated by Generator.Pairs.
+func createConnectedPairs(db neo4j.Session, dataset Dataset) error {
+	_, err := db.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		for i, edge := range dataset.Edges {
+			err := createConnectedPair(tx, dataset.Artifacts[i*2], dataset.Artifacts[i*2+1], edge)
 			if err != nil {
-				return
+				return nil, err
 			}
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+func queryAllNeo4jPairs(db neo4j.Session) (int, error) {
+	c, err := db.Run("MATCH (x:Entity)-[:RELATED]->(y:Entity) RETURN x", map[string]interface{}{})
+	if err != nil {
+		return 0, err
+	}
 
-			startDate = startDate.AddDate(0, 0, 1)
-			created++
+	retrieved := readAllFromCursor(c)
+
+	return retrieved, c.Err()
+}
+
+func queryAllNeo4jPairsOneYear(db neo4j.Session, year int) (int, error) {
+	params := map[string]interface{}{
+		"lower": fmt.Sprintf("%d", year),
+		"upper": fmt.Sprintf("%d", year+1),
+	}
+	c, err := db.Run("MATCH (x:Entity)-[:RELATED]->(y:Entity) WHERE x.create_time > $lower AND x.create_time < $upper RETURN x", params)
+	if err != nil {
+		return 0, err
+	}
+
+	retrieved := readAllFromCursor(c)
+
+	return retrieved, c.Err()
+}
+
+// createNeo4jGraph creates the entities and the relations connecting them in one transaction, both by UNWIND
+// of one parameter list. It is used for chains and neighbours.
+func createNeo4jGraph(db neo4j.Session, dataset Dataset) error {
+	_, err := db.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, runNeo4jGraph(tx, dataset)
+	})
+
+	return err
+}
+
+// CreateBulkNeo4jGraph creates the entities and the relations like createNeo4jGraph, but in an explicit
+// transaction which is not retried, so a failed bulk of the populate command is reported at once.
+func CreateBulkNeo4jGraph(db neo4j.Session, dataset Dataset) error {
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+	defer tx.Close()
+
+	if err := runNeo4jGraph(tx, dataset); err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "failed creating entities")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+func runNeo4jGraph(tx neo4j.Transaction, dataset Dataset) error {
+	entities := make([]interface{}, len(dataset.Artifacts))
+	for i, artifact := range dataset.Artifacts {
+		entities[i] = newNeo4jEntity(artifact).toStruct()
+	}
+
+	relations := make([]interface{}, len(dataset.Edges))
+	for i, edge := range dataset.Edges {
+		relations[i] = map[string]interface{}{
+			"from":     edge.From,
+			"to":       edge.To,
+			"relation": neo4jRelation{Body: edge.Body}.toStruct(),
 		}
+	}
+
+	res, err := tx.Run(`
+		UNWIND $batch AS props
+		CREATE (n:Entity)
+		SET n += props`,
+		map[string]interface{}{"batch": entities},
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := res.Consume(); err != nil {
+		return err
+	}
+
+	if len(relations) == 0 {
+		return nil
+	}
+
+	res, err = tx.Run(`
+		UNWIND $batch AS r
+		MATCH (x:Entity {key: r.from})
+		MATCH (y:Entity {key: r.to})
+		CREATE (x)-[rel:RELATED]->(y)
+		SET rel += r.relation`,
+		map[string]interface{}{"batch": relations},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = res.Consume()
+	return err
+}
+
+// queryNeo4jNeighbourN follows a variable-length pattern of exactly n relations. The length of the pattern
+// cannot be a parameter in Cypher, it is formatted into the query.
+func queryNeo4jNeighbourN(db neo4j.Session, key string, n int) (string, string, error) {
+	query := fmt.Sprintf(`
+		MATCH (:Entity {key: $key})-[:RELATED*%d]->(e:Entity)
+		RETURN e.key, e.name
+		LIMIT 1`, n)
+
+	record, err := neo4j.Single(db.Run(query, map[string]interface{}{"key": key}))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed searching in chain")
+	}
+
+	foundKey, _ := record.Values[0].(string)
+	name, _ := record.Values[1].(string)
 
+	return foundKey, name, nil
+}
+
+func sumNeo4jNeighbourNItems(db neo4j.Session, key string, n int) (int, error) {
+	query := fmt.Sprintf(`
+		MATCH p = (:Entity {key: $key})-[:RELATED*%d]->(:Entity)
+		WITH p LIMIT 1
+		RETURN toInteger(reduce(sum = 0, e IN nodes(p) | sum + e.item))`, n)
+
+	record, err := neo4j.Single(db.Run(query, map[string]interface{}{"key": key}))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed searching in chain")
+	}
+
+	sum, ok := record.Values[0].(int64)
+	if !ok {
+		return 0, errors.Errorf("unexpected sum %v", record.Values[0])
+	}
+
+	return int(sum), nil
+}
+
+func queryNeo4jSortedNeighbours(db neo4j.Session, key string) (int, error) {
+	c, err := db.Run(`
+		MATCH (:Entity {key: $key})-[:RELATED]->(n:Entity)
+		RETURN n.name
+		ORDER BY n.name`,
+		map[string]interface{}{"key": key},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	retrieved := readAllFromCursor(c)
+
+	return retrieved, c.Err()
+}
+
+// CountNeo4jEntities returns the number of entities and of the relations between them.
+func CountNeo4jEntities(db neo4j.Session) (entities int, relations int, err error) {
+	record, err := neo4j.Single(db.Run("MATCH (e:Entity) RETURN count(e)", nil))
+	if err != nil {
 		return
-	})
+	}
+	entities = int(record.Values[0].(int64))
+
+	record, err = neo4j.Single(db.Run("MATCH (:Entity)-[r:RELATED]->(:Entity) RETURN count(r)", nil))
+	if err != nil {
+		return
+	}
+	relations = int(record.Values[0].(int64))
 
 	return
 }
+
+// readNeo4jKeys returns the keys of all entities ordered by their creation time.
+func readNeo4jKeys(db neo4j.Session) ([]string, error) {
+	c, err := db.Run("MATCH (e:Entity) RETURN e.key ORDER BY e.create_time, e.key", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for c.Next() {
+		keys = append(keys, c.Record().Values[0].(string))
+	}
+
+	return keys, c.Err()
+}
+
+func deleteEntities(db neo4j.Session, keys []string) error {
+	params := map[string]interface{}{"keys": keys}
+	res, err := db.Run(`
+		MATCH (e:Entity)
+		WHERE e.key IN $keys
+		DETACH DELETE e`,
+		params,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = res.Consume()
+	return err
+}
+
+// neo4jBackend adapts the Neo4j functions to the Backend interface. Relations have no keys, they are removed
+// together with the entities.
+type neo4jBackend struct {
+	driver  neo4j.Driver
+	session neo4j.Session
+}
+
+// NewNeo4jBackend connects to Neo4j and makes sure the entity index exists.
+func NewNeo4jBackend(endpoint, username, password string) (Backend, error) {
+	driver, err := neo4j.NewDriver(endpoint, neo4j.BasicAuth(username, password, ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating neo4j driver")
+	}
+
+	session := driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+
+	if err := CreateNeo4jIndexes(session); err != nil {
+		session.Close()
+		driver.Close()
+		return nil, err
+	}
+
+	return &neo4jBackend{driver: driver, session: session}, nil
+}
+
+func (b *neo4jBackend) Name() string {
+	return "neo4j"
+}
+
+func (b *neo4jBackend) Count(_ context.Context) (int, int, error) {
+	return CountNeo4jEntities(b.session)
+}
+
+func (b *neo4jBackend) Keys(_ context.Context) ([]string, error) {
+	return readNeo4jKeys(b.session)
+}
+
+func (b *neo4jBackend) Create(_ context.Context, artifacts []Artifact) error {
+	return createEntities(b.session, artifacts)
+}
+
+func (b *neo4jBackend) BulkCreate(_ context.Context, artifacts []Artifact) error {
+	return bulkCreateEntities(b.session, artifacts)
+}
+
+func (b *neo4jBackend) Read(_ context.Context, key string) error {
+	return readOneEntity(b.session, key)
+}
+
+func (b *neo4jBackend) BulkRead(_ context.Context, keys []string) (int, error) {
+	return readBulkEntities(b.session, keys)
+}
+
+func (b *neo4jBackend) Update(_ context.Context, artifact Artifact) error {
+	return updateOneEntity(b.session, artifact)
+}
+
+func (b *neo4jBackend) BulkUpdate(_ context.Context, artifacts []Artifact) (int, error) {
+	return bulkUpdateEntities(b.session, artifacts)
+}
+
+func (b *neo4jBackend) Query(_ context.Context, keys []string) (int, error) {
+	return readMultipleEntities(b.session, keys)
+}
+
+func (b *neo4jBackend) CreatePairs(_ context.Context, dataset Dataset) error {
+	return createConnectedPairs(b.session, dataset)
+}
+
+func (b *neo4jBackend) QueryPairs(_ context.Context) (int, error) {
+	return queryAllNeo4jPairs(b.session)
+}
+
+func (b *neo4jBackend) QueryPairsInYear(_ context.Context, year int) (int, error) {
+	return queryAllNeo4jPairsOneYear(b.session, year)
+}
+
+func (b *neo4jBackend) CreateChain(_ context.Context, dataset Dataset) error {
+	return createNeo4jGraph(b.session, dataset)
+}
+
+func (b *neo4jBackend) QueryNeighbourN(_ context.Context, key string, n int) (Artifact, error) {
+	foundKey, name, err := queryNeo4jNeighbourN(b.session, key, n)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{Key: foundKey, Name: name}, nil
+}
+
+func (b *neo4jBackend) SumNeighbourItems(_ context.Context, key string, n int) (int, error) {
+	return sumNeo4jNeighbourNItems(b.session, key, n)
+}
+
+func (b *neo4jBackend) CreateNeighbours(_ context.Context, dataset Dataset) error {
+	return createNeo4jGraph(b.session, dataset)
+}
+
+func (b *neo4jBackend) QuerySortedNeighbours(_ context.Context, key string) (int, error) {
+	return queryNeo4jSortedNeighbours(b.session, key)
+}
+
+func (b *neo4jBackend) Cleanup(_ context.Context, graph Graph) error {
+	if graph.ArtifactKeys == nil {
+		return nil
+	}
+
+	return deleteEntities(b.session, graph.ArtifactKeys)
+}
+
+func (b *neo4jBackend) Close() error {
+	if err := b.session.Close(); err != nil {
+		return err
+	}
+
+	return b.driver.Close()
+}
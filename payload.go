@@ -0,0 +1,147 @@
+package db_bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// PayloadDistribution is the distribution the sizes of the text and binary payload fields are drawn from.
+type PayloadDistribution string
+
+const (
+	// PayloadFixed gives every artifact the configured size.
+	PayloadFixed PayloadDistribution = "fixed"
+
+	// PayloadUniform draws the sizes uniformly from [0, 2*size], their mean is the configured size.
+	PayloadUniform PayloadDistribution = "uniform"
+
+	// PayloadExponential draws the sizes from the exponential distribution of the configured mean, many small
+	// documents and a few large ones. The sizes are cut at payloadMaxFactor times the mean.
+	PayloadExponential PayloadDistribution = "exponential"
+)
+
+// PayloadDistributions lists all distributions of the payload sizes.
+var PayloadDistributions = []PayloadDistribution{PayloadFixed, PayloadUniform, PayloadExponential}
+
+// payloadMaxFactor limits the sizes drawn from the exponential distribution.
+const payloadMaxFactor = 10
+
+// payloadLetters are the characters of the generated texts.
+const payloadLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 "
+
+func (d PayloadDistribution) valid() bool {
+	for _, distribution := range PayloadDistributions {
+		if d == distribution {
+			return true
+		}
+	}
+	return false
+}
+
+// payloadSize draws a size of the mean by the configured distribution.
+func (g *Generator) payloadSize(mean int) int {
+
+	if mean <= 0 {
+		return 0
+	}
+
+	switch PayloadDistribution(g.Payload.Distribution) {
+	case PayloadUniform:
+		return g.intn(2*mean + 1)
+	case PayloadExponential:
+		return int(math.Min(g.rand.ExpFloat64()*float64(mean), payloadMaxFactor*float64(mean)))
+	default:
+		return mean
+	}
+}
+
+func (g *Generator) text(n int) string {
+	data := make([]byte, n)
+	_, _ = g.rand.Read(data)
+	for i, b := range data {
+		data[i] = payloadLetters[int(b)%len(payloadLetters)]
+	}
+	return string(data)
+}
+
+func (g *Generator) binary(n int) []byte {
+	data := make([]byte, n)
+	_, _ = g.rand.Read(data)
+	return data
+}
+
+// payload generates the payload of an artifact shaped by g.Payload, nil if it is empty:
+//
+//	field_0 .. field_N  short texts
+//	nested              {"level": 1, "value": ..., "nested": {"level": 2, ...}} nested Depth times
+//	array               Array numbers
+//	text                text of a size drawn around TextSize
+//	binary              random bytes of a size drawn around BinarySize
+func (g *Generator) payload() map[string]interface{} {
+
+	config := g.Payload
+	if config.empty() {
+		return nil
+	}
+
+	payload := make(map[string]interface{})
+
+	for i := 0; i < config.Fields; i++ {
+		payload[fmt.Sprintf("field_%d", i)] = g.text(16)
+	}
+
+	var nested map[string]interface{}
+	for level := config.Depth; level > 0; level-- {
+		object := map[string]interface{}{"level": level, "value": g.text(16)}
+		if nested != nil {
+			object["nested"] = nested
+		}
+		nested = object
+	}
+	if nested != nil {
+		payload["nested"] = nested
+	}
+
+	if config.Array > 0 {
+		array := make([]int64, config.Array)
+		for i := range array {
+			array[i] = g.rand.Int63n(1000000)
+		}
+		payload["array"] = array
+	}
+
+	if config.Text > 0 {
+		payload["text"] = g.text(g.payloadSize(config.Text))
+	}
+
+	if config.Binary > 0 {
+		payload["binary"] = g.binary(g.payloadSize(config.Binary))
+	}
+
+	return payload
+}
+
+// encodePayload returns the payload as a JSON document, or nil (NULL) if there is none. JSON has no binary
+// type, the binary field is encoded in base64.
+func encodePayload(payload map[string]interface{}) interface{} {
+
+	if payload == nil {
+		return nil
+	}
+
+	data, _ := json.Marshal(payload)
+	return string(data)
+}
+
+// flattenPayload stores the payload into the properties under the prefix, the nested objects under the dotted
+// paths of their fields (`payload.nested.value`). It is used by the databases without map properties.
+func flattenPayload(prefix string, payload map[string]interface{}, properties map[string]interface{}) {
+	for name, value := range payload {
+		if object, ok := value.(map[string]interface{}); ok {
+			flattenPayload(prefix+name+".", object, properties)
+			continue
+		}
+		properties[prefix+name] = value
+	}
+}
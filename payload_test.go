@@ -0,0 +1,141 @@
+package db_bench
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratorPayload(t *testing.T) {
+
+	assert.Nil(t, NewGenerator(1).Artifacts(1)[0].Payload)
+
+	config := PayloadConfig{Fields: 3, Depth: 2, Array: 5, Text: 100, Binary: 50, Distribution: string(PayloadFixed)}
+
+	generate := func(seed int64) []Artifact {
+		g := NewGenerator(seed)
+		g.Payload = config
+		return g.Artifacts(10)
+	}
+
+	artifacts := generate(1)
+	assert.Equal(t, artifacts, generate(1))
+
+	payload := artifacts[0].Payload
+	assert.Len(t, payload, 3+4)
+	assert.Len(t, payload["field_2"], 16)
+	assert.Len(t, payload["array"], 5)
+	assert.Len(t, payload["text"], 100)
+	assert.Len(t, payload["binary"], 50)
+
+	nested := payload["nested"].(map[string]interface{})
+	assert.Equal(t, 1, nested["level"])
+	assert.Equal(t, 2, nested["nested"].(map[string]interface{})["level"])
+	assert.NotContains(t, nested["nested"], "nested")
+
+	// The updates keep the payload as it is.
+	g := NewGenerator(1)
+	g.Payload = config
+	assert.Nil(t, g.Updates([]string{artifacts[0].Key})[0].Payload)
+}
+
+func TestGeneratorPayloadDistribution(t *testing.T) {
+
+	for _, distribution := range PayloadDistributions {
+		g := NewGenerator(1)
+		g.Payload = PayloadConfig{Text: 1000, Distribution: string(distribution)}
+
+		sizes := map[int]bool{}
+		var sum int
+		for _, artifact := range g.Artifacts(1000) {
+			size := len(artifact.Payload["text"].(string))
+			assert.LessOrEqual(t, size, payloadMaxFactor*1000, distribution)
+			sizes[size] = true
+			sum += size
+		}
+
+		assert.InDelta(t, 1000, sum/1000, 100, distribution)
+		if distribution == PayloadFixed {
+			assert.Len(t, sizes, 1)
+		} else {
+			assert.Greater(t, len(sizes), 100, distribution)
+		}
+	}
+}
+
+func TestEncodePayload(t *testing.T) {
+
+	assert.Nil(t, encodePayload(nil))
+
+	document := encodePayload(map[string]interface{}{"binary": []byte{1, 2}, "nested": map[string]interface{}{"level": 1}})
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(document.(string)), &decoded))
+	assert.Equal(t, "AQI=", decoded["binary"])
+	assert.Equal(t, map[string]interface{}{"level": float64(1)}, decoded["nested"])
+}
+
+func TestFlattenPayload(t *testing.T) {
+
+	properties := map[string]interface{}{"key": "a"}
+	flattenPayload("payload.", map[string]interface{}{
+		"text":   "text",
+		"array":  []int64{1, 2},
+		"nested": map[string]interface{}{"level": 1, "nested": map[string]interface{}{"level": 2}},
+	}, properties)
+
+	assert.Equal(t, map[string]interface{}{
+		"key":                         "a",
+		"payload.text":                "text",
+		"payload.array":               []int64{1, 2},
+		"payload.nested.level":        1,
+		"payload.nested.nested.level": 2,
+	}, properties)
+}
+
+// TestFlattenPayloadRoundTrip rebuilds a generated nested payload from its flat properties.
+func TestFlattenPayloadRoundTrip(t *testing.T) {
+
+	g := NewGenerator(1)
+	g.Payload = PayloadConfig{Fields: 2, Depth: 3, Array: 4, Text: 32, Binary: 8, Distribution: string(PayloadFixed)}
+	payload := g.payload()
+
+	properties := map[string]interface{}{"key": "a"}
+	flattenPayload("payload.", payload, properties)
+
+	assert.Contains(t, properties, "payload.nested.nested.nested.value")
+	assert.Equal(t, payload, unflattenPayload("payload.", properties))
+	assert.Nil(t, unflattenPayload("payload.", map[string]interface{}{"key": "a"}))
+}
+
+// unflattenPayload rebuilds the payload stored by flattenPayload from the properties under the prefix.
+func unflattenPayload(prefix string, properties map[string]interface{}) map[string]interface{} {
+
+	var payload map[string]interface{}
+
+	for name, value := range properties {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if payload == nil {
+			payload = make(map[string]interface{})
+		}
+
+		path := strings.Split(strings.TrimPrefix(name, prefix), ".")
+		object := payload
+		for _, field := range path[:len(path)-1] {
+			nested, ok := object[field].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				object[field] = nested
+			}
+			object = nested
+		}
+		object[path[len(path)-1]] = value
+	}
+
+	return payload
+}
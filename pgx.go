@@ -0,0 +1,581 @@
+package db_bench
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// The pgx functions work on the tables created by CreatePostgresTestingTables and send the same statements as the
+// lib/pq ones, one request per statement, but through the native pgx interface: the parameters are encoded in
+// binary, UUIDs as 16 bytes and timestamps as integers, and bulk inserts use the copy protocol as lib/pq does.
+
+func InitPgx(ctx context.Context, connStr string) (*pgx.Conn, error) {
+
+	conn, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening pgx connection")
+	}
+
+	return conn, nil
+}
+
+func createPgxTestingTables(ctx context.Context, conn *pgx.Conn) error {
+
+	_, err := conn.Exec(ctx, postgresArtifactTableStmt)
+	if err != nil {
+		return errors.Wrap(err, "failed creating artifact table")
+	}
+
+	_, err = conn.Exec(ctx, postgresPayloadColumnStmt)
+	if err != nil {
+		return errors.Wrap(err, "failed adding payload column")
+	}
+
+	_, err = conn.Exec(ctx, postgresEdgeTableStmt)
+	if err != nil {
+		return errors.Wrap(err, "failed creating edge table")
+	}
+
+	return nil
+}
+
+// pgxUUID converts the key to the value pgx encodes in binary.
+func pgxUUID(key string) (pgtype.UUID, error) {
+
+	id, err := uuid.Parse(key)
+	if err != nil {
+		return pgtype.UUID{}, errors.Wrapf(err, "failed parsing key %s", key)
+	}
+
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+func pgxUUIDs(keys []string) ([]pgtype.UUID, error) {
+
+	ids := make([]pgtype.UUID, len(keys))
+	for i, key := range keys {
+		id, err := pgxUUID(key)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func pgxKey(id pgtype.UUID) string {
+	return uuid.UUID(id.Bytes).String()
+}
+
+func createPgxArtifacts(ctx context.Context, conn *pgx.Conn, artifacts []Artifact) error {
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+
+	for _, artifact := range artifacts {
+
+		id, err := pgxUUID(artifact.Key)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+
+		stmt := `INSERT INTO artifacts(id, "name", description, item, create_time, payload) VALUES ($1, $2, $3, $4, $5, $6);`
+
+		_, err = tx.Exec(ctx, stmt, id, artifact.Name, artifact.Description, artifact.Item, artifact.CreateTime, encodePayload(artifact.Payload))
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return errors.Wrap(err, "failed inserting into table")
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+func pgxArtifactRows(artifacts []Artifact) ([][]interface{}, error) {
+
+	rows := make([][]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
+		id, err := pgxUUID(artifact.Key)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = []interface{}{id, artifact.Name, artifact.Description, artifact.Item, artifact.CreateTime, encodePayload(artifact.Payload)}
+	}
+
+	return rows, nil
+}
+
+func pgxEdgeRows(edges []Edge) ([][]interface{}, error) {
+
+	rows := make([][]interface{}, len(edges))
+	for i, edge := range edges {
+		ids, err := pgxUUIDs([]string{edge.Key, edge.From, edge.To})
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = []interface{}{ids[0], ids[1], ids[2], edge.Body}
+	}
+
+	return rows, nil
+}
+
+// createPgxGraph copies the artifacts and the edges connecting them in one transaction. It is used for bulk
+// creation, pairs, chains and neighbours.
+func createPgxGraph(ctx context.Context, conn *pgx.Conn, dataset Dataset) error {
+
+	artifactRows, err := pgxArtifactRows(dataset.Artifacts)
+	if err != nil {
+		return err
+	}
+
+	edgeRows, err := pgxEdgeRows(dataset.Edges)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+
+	for _, copy := range []struct {
+		table postgresTable
+		rows  [][]interface{}
+	}{
+		{postgresArtifactTable, artifactRows},
+		{postgresEdgeTable, edgeRows},
+	} {
+		if len(copy.rows) == 0 {
+			continue
+		}
+
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{copy.table.name}, copy.table.columns, pgx.CopyFromRows(copy.rows))
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return errors.Wrap(err, "failed inserting into table")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+func removeBulkPgxRows(ctx context.Context, conn *pgx.Conn, table string, keys []string) error {
+
+	ids, err := pgxUUIDs(keys)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(ctx, "DELETE FROM "+table+" WHERE id = ANY($1);", ids)
+	if err != nil {
+		return errors.Wrapf(err, "failed removing %s", table)
+	}
+
+	return nil
+}
+
+func readOnePgxArtifact(ctx context.Context, conn *pgx.Conn, key string) error {
+
+	id, err := pgxUUID(key)
+	if err != nil {
+		return err
+	}
+
+	var name string
+	var description pgtype.Text
+	var item int
+	var createTime time.Time
+	var payload []byte
+
+	err = conn.QueryRow(ctx, `SELECT "name", description, item, create_time, payload FROM artifacts WHERE id = $1;`, id).Scan(&name, &description, &item, &createTime, &payload)
+	if err != nil {
+		return errors.Wrap(err, "failed reading entry")
+	}
+
+	return nil
+}
+
+// readBulkPgxArtifacts reads the rows by one query with the IDs bound as a binary array.
+func readBulkPgxArtifacts(ctx context.Context, conn *pgx.Conn, keys []string) (int, error) {
+
+	ids, err := pgxUUIDs(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := conn.Query(ctx, `SELECT "name", description, item, create_time, payload FROM artifacts WHERE id = ANY($1);`, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var name string
+		var description pgtype.Text
+		var item int
+		var createTime time.Time
+		var payload []byte
+
+		err = rows.Scan(&name, &description, &item, &createTime, &payload)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed scanning variables")
+		}
+		count += 1
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed reading rows")
+	}
+
+	return count, nil
+}
+
+func updateOnePgxArtifact(ctx context.Context, conn *pgx.Conn, artifact Artifact) error {
+
+	id, err := pgxUUID(artifact.Key)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(ctx, `UPDATE artifacts SET "name" = $1, description = $2 WHERE id = $3;`, artifact.Name, artifact.Description, id)
+	if err != nil {
+		return errors.Wrap(err, "failed updating entry")
+	}
+
+	return nil
+}
+
+// updateBulkPgxArtifacts updates all rows by one statement joining the table with the bound arrays.
+func updateBulkPgxArtifacts(ctx context.Context, conn *pgx.Conn, artifacts []Artifact) (int, error) {
+
+	keys := make([]string, len(artifacts))
+	names := make([]string, len(artifacts))
+	descriptions := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		keys[i] = artifact.Key
+		names[i] = artifact.Name
+		descriptions[i] = artifact.Description
+	}
+
+	ids, err := pgxUUIDs(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := `UPDATE artifacts AS a SET "name" = u.name, description = u.description
+FROM unnest($1::uuid[], $2::text[], $3::text[]) AS u(id, name, description)
+WHERE a.id = u.id;`
+
+	tag, err := conn.Exec(ctx, stmt, ids, names, descriptions)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed updating table")
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// queryReadPgxArtifacts reads the rows one by one, pgx prepares the statement on its first use.
+func queryReadPgxArtifacts(ctx context.Context, conn *pgx.Conn, keys []string) (int, error) {
+
+	var count int
+	for _, key := range keys {
+		id, err := pgxUUID(key)
+		if err != nil {
+			return 0, err
+		}
+
+		var name string
+		err = conn.QueryRow(ctx, "SELECT name FROM artifacts WHERE id = $1;", id).Scan(&name)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed reading table")
+		}
+		count += 1
+	}
+
+	return count, nil
+}
+
+// countPgxQueryRows runs the query and counts the returned rows.
+func countPgxQueryRows(ctx context.Context, conn *pgx.Conn, stmt string, args ...interface{}) (int, error) {
+
+	rows, err := conn.Query(ctx, stmt, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var name string
+
+		err = rows.Scan(&name)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed scanning variables")
+		}
+		count += 1
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed reading rows")
+	}
+
+	return count, nil
+}
+
+func queryAllPgxPairs(ctx context.Context, conn *pgx.Conn) (int, error) {
+
+	stmt := "SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id;"
+
+	return countPgxQueryRows(ctx, conn, stmt)
+}
+
+func queryAllPgxPairsOneYear(ctx context.Context, conn *pgx.Conn, year int) (int, error) {
+
+	stmt := "SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id WHERE date_part('year', t.create_time) = $1;"
+
+	return countPgxQueryRows(ctx, conn, stmt, year)
+}
+
+func queryPgxNeighbourN(ctx context.Context, conn *pgx.Conn, startingKey string, i int) (string, string, error) {
+
+	startingID, err := pgxUUID(startingKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	stmt := fmt.Sprintf(postgresNeighbourNStmt, "$1", "$2", "$2")
+
+	var id pgtype.UUID
+	var name string
+	var n int
+
+	err = conn.QueryRow(ctx, stmt, startingID, i).Scan(&id, &name, &n)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed searching in chain")
+	}
+
+	return pgxKey(id), name, nil
+}
+
+func sumPgxNeighbourNItems(ctx context.Context, conn *pgx.Conn, startingKey string, i int) (int, error) {
+
+	startingID, err := pgxUUID(startingKey)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := fmt.Sprintf(postgresSumNeighbourNStmt, "$1", "$2")
+
+	var sum int
+
+	err = conn.QueryRow(ctx, stmt, startingID, i).Scan(&sum)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed searching in chain")
+	}
+
+	return sum, nil
+}
+
+func queryPgxSortedNeighbours(ctx context.Context, conn *pgx.Conn, key string) (int, error) {
+
+	id, err := pgxUUID(key)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := "SELECT a.name FROM edges e INNER JOIN artifacts a ON e.to = a.id WHERE e.from = $1 GROUP BY a.name;"
+
+	return countPgxQueryRows(ctx, conn, stmt, id)
+}
+
+// readPgxKeys returns the keys of all artifacts ordered by their creation time.
+func readPgxKeys(ctx context.Context, conn *pgx.Conn) ([]string, error) {
+
+	rows, err := conn.Query(ctx, `SELECT id FROM artifacts ORDER BY create_time, id;`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var id pgtype.UUID
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed scanning variables")
+		}
+		keys = append(keys, pgxKey(id))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed reading rows")
+	}
+
+	return keys, nil
+}
+
+func countPgxRows(ctx context.Context, conn *pgx.Conn) (int, int, error) {
+
+	var artifactCounter int
+	var edgeCounter int
+
+	err := conn.QueryRow(ctx, "SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
+	}
+
+	err = conn.QueryRow(ctx, "SELECT COUNT(*) FROM edges;").Scan(&edgeCounter)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed counting rows in edge table")
+	}
+
+	return artifactCounter, edgeCounter, nil
+}
+
+// pgxBackend adapts the pgx functions to the Backend interface. It shares the tables with postgresBackend, so
+// both drivers can be compared on the same server. The connection is not safe for concurrent use, every worker
+// opens its own backend.
+type pgxBackend struct {
+	conn *pgx.Conn
+}
+
+// NewPgxBackend connects to PostgreSQL by pgx and makes sure the testing tables exist.
+func NewPgxBackend(connStr string) (Backend, error) {
+
+	ctx := context.Background()
+
+	conn, err := InitPgx(ctx, connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed initializing pgx")
+	}
+
+	if err := createPgxTestingTables(ctx, conn); err != nil {
+		_ = conn.Close(ctx)
+		return nil, errors.Wrap(err, "failed creating testing tables")
+	}
+
+	return &pgxBackend{conn: conn}, nil
+}
+
+func (b *pgxBackend) Name() string {
+	return "pgx"
+}
+
+func (b *pgxBackend) Count(ctx context.Context) (int, int, error) {
+	return countPgxRows(ctx, b.conn)
+}
+
+func (b *pgxBackend) Keys(ctx context.Context) ([]string, error) {
+	return readPgxKeys(ctx, b.conn)
+}
+
+func (b *pgxBackend) Create(ctx context.Context, artifacts []Artifact) error {
+	return createPgxArtifacts(ctx, b.conn, artifacts)
+}
+
+func (b *pgxBackend) BulkCreate(ctx context.Context, artifacts []Artifact) error {
+	return createPgxGraph(ctx, b.conn, Dataset{Artifacts: artifacts})
+}
+
+func (b *pgxBackend) Read(ctx context.Context, key string) error {
+	return readOnePgxArtifact(ctx, b.conn, key)
+}
+
+func (b *pgxBackend) BulkRead(ctx context.Context, keys []string) (int, error) {
+	return readBulkPgxArtifacts(ctx, b.conn, keys)
+}
+
+func (b *pgxBackend) Update(ctx context.Context, artifact Artifact) error {
+	return updateOnePgxArtifact(ctx, b.conn, artifact)
+}
+
+func (b *pgxBackend) BulkUpdate(ctx context.Context, artifacts []Artifact) (int, error) {
+	return updateBulkPgxArtifacts(ctx, b.conn, artifacts)
+}
+
+func (b *pgxBackend) Query(ctx context.Context, keys []string) (int, error) {
+	return queryReadPgxArtifacts(ctx, b.conn, keys)
+}
+
+func (b *pgxBackend) CreatePairs(ctx context.Context, dataset Dataset) error {
+	return createPgxGraph(ctx, b.conn, dataset)
+}
+
+func (b *pgxBackend) QueryPairs(ctx context.Context) (int, error) {
+	return queryAllPgxPairs(ctx, b.conn)
+}
+
+func (b *pgxBackend) QueryPairsInYear(ctx context.Context, year int) (int, error) {
+	return queryAllPgxPairsOneYear(ctx, b.conn, year)
+}
+
+func (b *pgxBackend) CreateChain(ctx context.Context, dataset Dataset) error {
+	return createPgxGraph(ctx, b.conn, dataset)
+}
+
+func (b *pgxBackend) QueryNeighbourN(ctx context.Context, key string, n int) (Artifact, error) {
+
+	id, name, err := queryPgxNeighbourN(ctx, b.conn, key, n)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{Key: id, Name: name}, nil
+}
+
+func (b *pgxBackend) SumNeighbourItems(ctx context.Context, key string, n int) (int, error) {
+	return sumPgxNeighbourNItems(ctx, b.conn, key, n)
+}
+
+func (b *pgxBackend) CreateNeighbours(ctx context.Context, dataset Dataset) error {
+	return createPgxGraph(ctx, b.conn, dataset)
+}
+
+func (b *pgxBackend) QuerySortedNeighbours(ctx context.Context, key string) (int, error) {
+	return queryPgxSortedNeighbours(ctx, b.conn, key)
+}
+
+func (b *pgxBackend) Cleanup(ctx context.Context, graph Graph) error {
+
+	if graph.EdgeKeys != nil {
+		if err := removeBulkPgxRows(ctx, b.conn, "edges", graph.EdgeKeys); err != nil {
+			return err
+		}
+	}
+
+	if graph.ArtifactKeys != nil {
+		if err := removeBulkPgxRows(ctx, b.conn, "artifacts", graph.ArtifactKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *pgxBackend) Close() error {
+	return b.conn.Close(context.Background())
+}
@@ -0,0 +1,42 @@
+package db_bench
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgxSuite(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewPgxBackend(config.Postgres.ConnStr)
+	require.NoError(t, err)
+
+	runScenarios(t, config, backend, NewMemoryBackend())
+}
+
+func TestPgxQueries(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewPgxBackend(config.Postgres.ConnStr)
+	require.NoError(t, err)
+
+	runQueries(t, backend)
+}
+
+func TestPgxUUID(t *testing.T) {
+
+	key := uuid.NewString()
+
+	id, err := pgxUUID(key)
+	require.NoError(t, err)
+	assert.True(t, id.Valid)
+	assert.Equal(t, key, pgxKey(id))
+
+	_, err = pgxUUID("not-a-uuid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,441 @@
+package db_bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrPopulated is returned by Populator.Run when the database already holds the requested number of entries.
+var ErrPopulated = errors.New("db is already populated")
+
+// PopulateProgress is the state of a population after a written bulk.
+type PopulateProgress struct {
+
+	// Stored is the number of entries stored before the run, Total the number of entries the run writes.
+	Stored int
+	Total  int
+
+	// Rows and Edges are the numbers of entries and edges written by the run so far.
+	Rows  int
+	Edges int
+
+	// RowsPerSec is the average throughput of the run and ETA the estimated time to write the rest.
+	RowsPerSec float64
+	ETA        time.Duration
+}
+
+// Populator fills a database with a graph generated by TopologyGenerator, bulk by bulk, by several writers at
+// once. Before a bulk is written its keys are saved to a checkpoint file of the writer and the file is removed
+// when the bulk is written. An interrupted run leaves the files of its unfinished bulks, the next run removes
+// their entries from the database first, so it continues from the complete bulks only.
+//
+// The edges of a bulk connect to the entries of the earlier bulks. The entries are written in parallel, but
+// the edges of a bulk are written only after all earlier bulks are complete, so an edge never points to a
+// missing entry nor is an unfinished bulk referenced by a complete one.
+type Populator struct {
+
+	// Backend counts the stored entries and removes the unfinished bulks, it is used by one goroutine.
+	Backend Backend
+
+	// Write writes one bulk, entries and edges in one transaction if the database allows. It is called by all
+	// writers at once.
+	Write func(ctx context.Context, dataset Dataset) error
+
+	Config PopulateConfig
+
+	// Payload shapes the payload of the generated entries.
+	Payload PayloadConfig
+
+	// Progress is called before the first bulk and after every written bulk, by one writer at a time.
+	Progress func(PopulateProgress)
+
+	// NewGenerator creates the generator of the data, NewGenerator of the package if nil, its clock continuing
+	// after the entries generated by the previous runs (saved in the checkpoint directory) or stored. Its seed
+	// is derived from Config.Seed and the number of stored entries, so a resumed run does not repeat the keys
+	// nor the times.
+	NewGenerator func(seed int64) *Generator
+}
+
+// Run populates the database up to Config.N entries.
+func (p *Populator) Run(ctx context.Context) error {
+
+	checkpoint := populateCheckpoint{dir: filepath.Join(p.Config.Checkpoint, p.Backend.Name())}
+
+	if err := p.resume(ctx, checkpoint); err != nil {
+		return err
+	}
+
+	artifacts, edges, err := p.Backend.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed counting stored entries")
+	}
+
+	if artifacts >= p.Config.N {
+		return ErrPopulated
+	}
+
+	// The edges already in the database count into the target, the new ones connect the new entries only.
+	edgeTarget := p.Config.Edges - edges
+	if edgeTarget < 0 {
+		edgeTarget = 0
+	}
+
+	total := p.Config.N - artifacts
+
+	// The clock continues after all artifacts generated by the previous runs, the writers of a run may leave
+	// gaps of removed bulks among the stored ones.
+	ticks, err := checkpoint.loadClock()
+	if err != nil {
+		return err
+	}
+	if ticks < artifacts {
+		ticks = artifacts
+	}
+
+	newGenerator := p.NewGenerator
+	if newGenerator == nil {
+		newGenerator = func(seed int64) *Generator {
+			g := NewGenerator(seed)
+			g.Clock = StepClock(storedEpoch(ticks), time.Millisecond)
+			return g
+		}
+	}
+	gen := newGenerator(DeriveSeed(int64(p.Config.Seed), "populate", artifacts))
+	gen.Payload = p.Payload
+
+	generator, err := NewTopologyGenerator(gen, Topology(p.Config.Topology), total, edgeTarget)
+	if err != nil {
+		return errors.Wrap(err, "failed creating topology generator")
+	}
+
+	if err := os.MkdirAll(checkpoint.dir, 0755); err != nil {
+		return errors.Wrap(err, "failed creating checkpoint directory")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	complete := newPopulateWatermark()
+
+	var once sync.Once
+	var firstErr error
+
+	bulks := make(chan populateBulk)
+	go func() {
+		defer close(bulks)
+		for i := 0; ; i++ {
+			dataset := generator.Next(p.Config.Chunk)
+			if len(dataset.Artifacts) == 0 {
+				return
+			}
+			generated, _ := generator.Generated()
+			if err := checkpoint.saveClock(ticks + generated); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+					complete.fail()
+				})
+				return
+			}
+			select {
+			case bulks <- populateBulk{index: i, dataset: dataset}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workers := p.Config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	progress := populateProgress{
+		progress: PopulateProgress{Stored: artifacts, Total: total},
+		start:    time.Now(),
+		report:   p.Progress,
+	}
+	progress.add(0, 0)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for bulk := range bulks {
+				if ctx.Err() != nil {
+					return
+				}
+				// A failed writer stops, its checkpoint keeps the keys of the failed bulk.
+				if err := p.writeBulk(ctx, checkpoint, w, bulk, workers, complete); err != nil {
+					once.Do(func() {
+						firstErr = errors.Wrapf(err, "bulk %d failed", bulk.index)
+						cancel()
+						complete.fail()
+					})
+					return
+				}
+				progress.add(len(bulk.dataset.Artifacts), len(bulk.dataset.Edges))
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// A complete run leaves no gaps, the next one continues after the stored artifacts.
+	return checkpoint.clearClock()
+}
+
+// resume removes the entries of the bulks an interrupted run did not finish.
+func (p *Populator) resume(ctx context.Context, checkpoint populateCheckpoint) error {
+
+	pending, err := checkpoint.load()
+	if err != nil {
+		return err
+	}
+
+	for name, graph := range pending {
+		if err := p.Backend.Cleanup(ctx, graph); err != nil {
+			return errors.Wrap(err, "failed removing unfinished bulk")
+		}
+		if err := os.Remove(name); err != nil {
+			return errors.Wrap(err, "failed removing checkpoint")
+		}
+	}
+
+	return nil
+}
+
+// writeBulk writes the bulk in one call by a single writer. Several writers write the entries first and the
+// edges after all earlier bulks are complete.
+func (p *Populator) writeBulk(ctx context.Context, checkpoint populateCheckpoint, w int, bulk populateBulk, workers int, complete *populateWatermark) error {
+
+	if err := checkpoint.begin(w, bulk.dataset.Keys()); err != nil {
+		return err
+	}
+
+	if workers == 1 || len(bulk.dataset.Edges) == 0 {
+		if err := p.Write(ctx, bulk.dataset); err != nil {
+			return err
+		}
+	} else {
+		if err := p.Write(ctx, Dataset{Artifacts: bulk.dataset.Artifacts}); err != nil {
+			return err
+		}
+		if err := complete.wait(bulk.index); err != nil {
+			return err
+		}
+		if err := p.Write(ctx, Dataset{Edges: bulk.dataset.Edges}); err != nil {
+			return err
+		}
+	}
+
+	if err := checkpoint.end(w); err != nil {
+		return err
+	}
+
+	complete.done(bulk.index)
+
+	return nil
+}
+
+type populateBulk struct {
+	index   int
+	dataset Dataset
+}
+
+// populateCheckpoint keeps the keys of the bulk being written by a writer in the file of the writer.
+type populateCheckpoint struct {
+	dir string
+}
+
+func (c populateCheckpoint) path(w int) string {
+	return filepath.Join(c.dir, fmt.Sprintf("writer-%d.json", w))
+}
+
+// begin saves the keys of the bulk.
+func (c populateCheckpoint) begin(w int, graph Graph) error {
+	return c.save(c.path(w), graph)
+}
+
+// save writes the value to the file, the file is replaced at once so it is never found half-written.
+func (c populateCheckpoint) save(path string, value interface{}) error {
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed encoding checkpoint")
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, "failed writing checkpoint")
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "failed writing checkpoint")
+	}
+
+	return nil
+}
+
+func (c populateCheckpoint) clockPath() string {
+	return filepath.Join(c.dir, "clock.json")
+}
+
+// saveClock saves the number of artifacts generated from GeneratorEpoch on, before they are written.
+func (c populateCheckpoint) saveClock(ticks int) error {
+	return c.save(c.clockPath(), ticks)
+}
+
+// loadClock returns the number of artifacts generated by the previous runs, zero if there were none.
+func (c populateCheckpoint) loadClock() (int, error) {
+
+	data, err := os.ReadFile(c.clockPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading clock checkpoint")
+	}
+
+	var ticks int
+	if err := json.Unmarshal(data, &ticks); err != nil {
+		return 0, errors.Wrap(err, "failed decoding clock checkpoint")
+	}
+
+	return ticks, nil
+}
+
+// clearClock removes the number of generated artifacts.
+func (c populateCheckpoint) clearClock() error {
+	if err := os.Remove(c.clockPath()); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed removing clock checkpoint")
+	}
+	return nil
+}
+
+func (c populateCheckpoint) end(w int) error {
+	if err := os.Remove(c.path(w)); err != nil {
+		return errors.Wrap(err, "failed removing checkpoint")
+	}
+	return nil
+}
+
+// load returns the keys of the unfinished bulks by their checkpoint files.
+func (c populateCheckpoint) load() (map[string]Graph, error) {
+
+	names, err := filepath.Glob(filepath.Join(c.dir, "writer-*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing checkpoints")
+	}
+
+	pending := make(map[string]Graph, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading checkpoint")
+		}
+
+		var graph Graph
+		if err := json.Unmarshal(data, &graph); err != nil {
+			return nil, errors.Wrapf(err, "failed decoding checkpoint %s", name)
+		}
+		pending[name] = graph
+	}
+
+	return pending, nil
+}
+
+// populateWatermark tracks the complete bulks, wait blocks until all bulks before the given one are complete.
+type populateWatermark struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	complete map[int]bool
+
+	// next is the first bulk which is not complete.
+	next   int
+	failed bool
+}
+
+func newPopulateWatermark() *populateWatermark {
+	w := &populateWatermark{complete: make(map[int]bool)}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+func (w *populateWatermark) done(i int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.complete[i] = true
+	for w.complete[w.next] {
+		delete(w.complete, w.next)
+		w.next++
+	}
+	w.cond.Broadcast()
+}
+
+func (w *populateWatermark) wait(i int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.next < i && !w.failed {
+		w.cond.Wait()
+	}
+
+	if w.failed {
+		return errors.New("population failed")
+	}
+
+	return nil
+}
+
+// fail releases the waiting writers.
+func (w *populateWatermark) fail() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.failed = true
+	w.cond.Broadcast()
+}
+
+// populateProgress accumulates the written entries and reports the throughput.
+type populateProgress struct {
+	mu       sync.Mutex
+	progress PopulateProgress
+	start    time.Time
+	report   func(PopulateProgress)
+}
+
+func (p *populateProgress) add(rows, edges int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.progress.Rows += rows
+	p.progress.Edges += edges
+
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed > 0 {
+		p.progress.RowsPerSec = float64(p.progress.Rows) / elapsed
+	}
+	if p.progress.RowsPerSec > 0 {
+		remaining := float64(p.progress.Total - p.progress.Rows)
+		p.progress.ETA = time.Duration(remaining / p.progress.RowsPerSec * float64(time.Second))
+	}
+
+	if p.report != nil {
+		p.report(p.progress)
+	}
+}
@@ -0,0 +1,142 @@
+package db_bench
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPopulator(t *testing.T, backend Backend) *Populator {
+	return &Populator{
+		Backend: backend,
+		Write:   backend.CreatePairs,
+		Config: PopulateConfig{
+			N:          1000,
+			Chunk:      64,
+			Topology:   string(TopologyPreferential),
+			Edges:      3000,
+			Workers:    4,
+			Checkpoint: t.TempDir(),
+		},
+	}
+}
+
+// requireConnected checks that every edge of the memory backend connects stored artifacts.
+func requireConnected(t *testing.T, backend Backend) {
+	memory := backend.(*memoryBackend)
+	for key, edge := range memory.edges {
+		require.Contains(t, memory.artifacts, edge.From, "edge %s from a removed artifact", key)
+		require.Contains(t, memory.artifacts, edge.To, "edge %s to a removed artifact", key)
+	}
+}
+
+func TestPopulate(t *testing.T) {
+
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	populator := testPopulator(t, backend)
+
+	var last PopulateProgress
+	populator.Progress = func(progress PopulateProgress) {
+		assert.GreaterOrEqual(t, progress.Rows, last.Rows)
+		last = progress
+	}
+
+	require.NoError(t, populator.Run(ctx))
+
+	artifacts, edges, err := backend.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, artifacts)
+	assert.Equal(t, 3000, edges)
+	requireConnected(t, backend)
+
+	assert.Equal(t, 1000, last.Rows)
+	assert.Equal(t, 1000, last.Total)
+	assert.Equal(t, 3000, last.Edges)
+	assert.Zero(t, last.ETA)
+
+	checkpoints, err := filepath.Glob(filepath.Join(populator.Config.Checkpoint, "memory", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+	assert.ErrorIs(t, populator.Run(ctx), ErrPopulated)
+}
+
+func TestPopulateResume(t *testing.T) {
+
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	populator := testPopulator(t, backend)
+
+	// The fifth write stores its data and fails anyway, as a write interrupted before its acknowledgement.
+	var writes int32
+	populator.Write = func(ctx context.Context, dataset Dataset) error {
+		err := backend.CreatePairs(ctx, dataset)
+		if atomic.AddInt32(&writes, 1) == 5 {
+			return errors.New("connection lost")
+		}
+		return err
+	}
+
+	require.Error(t, populator.Run(ctx))
+
+	checkpoints, err := filepath.Glob(filepath.Join(populator.Config.Checkpoint, "memory", "writer-*.json"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, checkpoints)
+
+	// The second run removes the unfinished bulks and completes the rest.
+	populator.Write = backend.CreatePairs
+	require.NoError(t, populator.Run(ctx))
+
+	artifacts, edges, err := backend.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, artifacts)
+	assert.Equal(t, 3000, edges)
+	requireConnected(t, backend)
+
+	// The resumed run continues the clock, the artifacts of both runs do not share their times.
+	times := make(map[time.Time]bool)
+	for _, artifact := range backend.(*memoryBackend).artifacts {
+		times[artifact.CreateTime] = true
+	}
+	assert.Len(t, times, 1000)
+}
+
+// TestPopulatedPairs queries the pairs of a database with pre-populated edges, they count into all pairs and
+// the query within one year is skipped.
+func TestPopulatedPairs(t *testing.T) {
+
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	require.NoError(t, testPopulator(t, backend).Run(ctx))
+
+	bench, err := NewBench(ctx, backend)
+	require.NoError(t, err)
+	bench.Oracle = NewMemoryBackend()
+	require.Equal(t, 3000, bench.StaticEdgeCount)
+
+	for _, name := range []string{"CreateConnectedPairs10000", "QueryAllConnectedPairs10000"} {
+		scenario, ok := FindScenario(name)
+		require.True(t, ok)
+		_, err := bench.Run(ctx, scenario)
+		require.NoError(t, err, name)
+	}
+
+	scenario, ok := FindScenario("QueryAllConnectedPairsOneYear10000")
+	require.True(t, ok)
+	_, err = bench.Run(ctx, scenario)
+	require.ErrorIs(t, err, ErrSkipped)
+
+	require.NoError(t, bench.Close(ctx))
+
+	artifacts, edges, err := backend.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, artifacts)
+	assert.Equal(t, 3000, edges)
+}
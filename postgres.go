@@ -1,14 +1,14 @@
 package db_bench
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	"math/rand"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 func InitPostgres(connStr string) (*sql.DB, error) {
@@ -21,18 +21,20 @@ func InitPostgres(connStr string) (*sql.DB, error) {
 	return db, nil
 }
 
-func CreatePostgresTestingTables(db *sql.DB) error {
-
-	artifactSTMT := `CREATE TABLE IF NOT EXISTS artifacts
+const postgresArtifactTableStmt = `CREATE TABLE IF NOT EXISTS artifacts
 (
     id           UUID PRIMARY KEY,
     "name"       TEXT NOT NULL,
     description  TEXT,
     item         INTEGER DEFAULT 1,
-    create_time  TIMESTAMP NOT NULL DEFAULT CLOCK_TIMESTAMP()
+    create_time  TIMESTAMP NOT NULL DEFAULT CLOCK_TIMESTAMP(),
+    payload      JSONB
 );`
 
-	edgeSTMT := `CREATE TABLE IF NOT EXISTS edges
+// postgresPayloadColumnStmt adds the payload column to an artifact table created before it existed.
+const postgresPayloadColumnStmt = `ALTER TABLE artifacts ADD COLUMN IF NOT EXISTS payload JSONB;`
+
+const postgresEdgeTableStmt = `CREATE TABLE IF NOT EXISTS edges
 (
     id      UUID PRIMARY KEY,
     "from"  UUID REFERENCES artifacts,
@@ -40,12 +42,19 @@ func CreatePostgresTestingTables(db *sql.DB) error {
     body    TEXT
 );`
 
-	_, err := db.Exec(artifactSTMT)
+func CreatePostgresTestingTables(db *sql.DB) error {
+
+	_, err := db.Exec(postgresArtifactTableStmt)
 	if err != nil {
 		return errors.Wrap(err, "failed creating artifact table")
 	}
 
-	_, err = db.Exec(edgeSTMT)
+	_, err = db.Exec(postgresPayloadColumnStmt)
+	if err != nil {
+		return errors.Wrap(err, "failed adding payload column")
+	}
+
+	_, err = db.Exec(postgresEdgeTableStmt)
 	if err != nil {
 		return errors.Wrap(err, "failed creating edge table")
 	}
@@ -53,111 +62,139 @@ func CreatePostgresTestingTables(db *sql.DB) error {
 	return nil
 }
 
-func createPostgresArtifacts(db *sql.DB, n int) ([]string, int, error) {
+func createPostgresArtifacts(db *sql.DB, artifacts []Artifact) error {
 
 	tx, err := db.Begin()
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed creating transaction")
+		return errors.Wrap(err, "failed creating transaction")
 	}
 
-	var ids []string
-
-	for i := 0; i < n; i++ {
+	for _, artifact := range artifacts {
 
-		stmt := `INSERT INTO artifacts(id, "name", description) VALUES ($1, $2, $3);`
+		stmt := `INSERT INTO artifacts(id, "name", description, item, create_time, payload) VALUES ($1, $2, $3, $4, $5, $6);`
 
-		id, _ := uuid.NewUUID()
-		name := fmt.Sprintf("name-%d", i)
-		description := fmt.Sprintf("description-%d", i)
-
-		_, err := tx.Exec(stmt, id, name, description)
+		_, err := tx.Exec(stmt, artifact.Key, artifact.Name, artifact.Description, artifact.Item, artifact.CreateTime, encodePayload(artifact.Payload))
 		if err != nil {
-			return nil, 0, errors.Wrap(err, "failed inserting into table")
+			_ = tx.Rollback()
+			return errors.Wrap(err, "failed inserting into table")
 		}
-
-		ids = append(ids, id.String())
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed committing transaction")
+		return errors.Wrap(err, "failed committing transaction")
 	}
 
-	var counter int
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&counter)
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed counting rows")
-	}
+	return nil
+}
 
-	return ids, counter, nil
+// CreateBulkPostgresGraph creates the artifacts and the edges by COPY in one transaction, the fastest bulk
+// strategy.
+func CreateBulkPostgresGraph(db *sql.DB, dataset Dataset) error {
+	return createPostgresGraph(db, dataset, PostgresBulkCopy)
 }
 
-func createBulkPostgresArtifacts(db *sql.DB, n int) ([]string, int, error) {
+func insertPostgresArtifactStmt(artifact Artifact) string {
+	payload := "NULL"
+	if document := encodePayload(artifact.Payload); document != nil {
+		payload = pq.QuoteLiteral(document.(string))
+	}
+	return fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description, item, create_time, payload) VALUES ('%s', '%s', '%s', %d, '%s', %s);",
+		artifact.Key, artifact.Name, artifact.Description, artifact.Item, artifact.CreateTime.Format(time.RFC3339Nano), payload)
+}
 
-	var stmt string
-	var ids []string
+func insertPostgresEdgeStmt(edge Edge) string {
+	return fmt.Sprintf("INSERT INTO edges(id, \"from\", \"to\", body) VALUES ('%s', '%s', '%s', '%s');", edge.Key, edge.From, edge.To, edge.Body)
+}
+
+// createBulkPostgresArtifactsInlined sends one INSERT per artifact with the values formatted into the
+// statements, all in one request.
+func createBulkPostgresArtifactsInlined(db *sql.DB, artifacts []Artifact) error {
 
-	for i := 0; i < n; i++ {
-		id, _ := uuid.NewUUID()
-		name := fmt.Sprintf("name-%d", i)
-		description := fmt.Sprintf("description-%d", i)
-		stmt = stmt + fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', '%s', '%s');", id, name, description)
+	var stmt strings.Builder
 
-		ids = append(ids, id.String())
+	for _, artifact := range artifacts {
+		stmt.WriteString(insertPostgresArtifactStmt(artifact))
 	}
 
-	_, err := db.Exec(stmt)
+	_, err := db.Exec(stmt.String())
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed inserting into table")
+		return errors.Wrap(err, "failed inserting into table")
 	}
 
-	var counter int
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&counter)
+	return nil
+}
+
+func removeBulkPostgresRows(db *sql.DB, table string, ids []string) error {
+
+	_, err := db.Exec("DELETE FROM "+table+" WHERE id = ANY($1);", pq.Array(ids))
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed counting rows")
+		return errors.Wrapf(err, "failed removing %s", table)
 	}
 
-	return ids, counter, nil
+	return nil
 }
 
-func removeBulkPostgresArtifacts(db *sql.DB, ids []string) error {
-
-	var stmt string
+func readOnePostgresArtifact(db *sql.DB, id string) error {
 
-	for _, id := range ids {
-		stmt = stmt + fmt.Sprintf("DELETE FROM artifacts WHERE id='%s';", id)
-	}
+	var name string
+	var description sql.NullString
+	var item int
+	var createTime time.Time
+	var payload []byte
 
-	_, err := db.Exec(stmt)
+	err := db.QueryRow(`SELECT "name", description, item, create_time, payload FROM artifacts WHERE id = $1;`, id).Scan(&name, &description, &item, &createTime, &payload)
 	if err != nil {
-		return errors.Wrap(err, "failed removing artifacts")
+		return errors.Wrap(err, "failed reading entry")
 	}
 
 	return nil
 }
 
-func removeBulkPostgresEdges(db *sql.DB, ids []string) error {
+// readBulkPostgresArtifacts reads the rows by one query with the IDs bound as an array.
+func readBulkPostgresArtifacts(db *sql.DB, ids []string) (int, error) {
 
-	var stmt string
+	rows, err := db.Query(`SELECT "name", description, item, create_time, payload FROM artifacts WHERE id = ANY($1);`, pq.Array(ids))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
 
-	for _, id := range ids {
-		stmt = stmt + fmt.Sprintf("DELETE FROM edges WHERE id='%s';", id)
+	var count int
+	for rows.Next() {
+		var name string
+		var description sql.NullString
+		var item int
+		var createTime time.Time
+		var payload []byte
+
+		err = rows.Scan(&name, &description, &item, &createTime, &payload)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed scanning variables")
+		}
+		count += 1
 	}
 
-	_, err := db.Exec(stmt)
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed reading rows")
+	}
+
+	return count, nil
+}
+
+func updateOnePostgresArtifact(db *sql.DB, artifact Artifact) error {
+
+	_, err := db.Exec(`UPDATE artifacts SET "name" = $1, description = $2 WHERE id = $3;`, artifact.Name, artifact.Description, artifact.Key)
 	if err != nil {
-		return errors.Wrap(err, "failed removing edges")
+		return errors.Wrap(err, "failed updating entry")
 	}
 
 	return nil
 }
 
-func updateOnePostgresArtifact(db *sql.DB, id string) error {
+func updateOnePostgresArtifactInlined(db *sql.DB, artifact Artifact) error {
 
-	i := rand.Intn(1000)
-	name := fmt.Sprintf("new-name-%d", i)
-	description := fmt.Sprintf("new-description-%d", i)
-	stmt := fmt.Sprintf("UPDATE artifacts SET \"name\" = '%s', description = '%s' WHERE id = '%s';", name, description, id)
+	stmt := fmt.Sprintf("UPDATE artifacts SET \"name\" = '%s', description = '%s' WHERE id = '%s';", artifact.Name, artifact.Description, artifact.Key)
 
 	_, err := db.Exec(stmt)
 	if err != nil {
@@ -167,138 +204,172 @@ func updateOnePostgresArtifact(db *sql.DB, id string) error {
 	return nil
 }
 
-func updateBulkPostgresArtifacts(db *sql.DB, ids []string) (int, error) {
+// updateBulkPostgresArtifacts updates all rows by one statement joining the table with the bound arrays.
+func updateBulkPostgresArtifacts(db *sql.DB, artifacts []Artifact) (int, error) {
 
-	var stmt string
-
-	for _, id := range ids {
-		i := rand.Intn(1000)
-		name := fmt.Sprintf("new-name-%d", i)
-		description := fmt.Sprintf("new-description-%d", i)
-		stmt = stmt + fmt.Sprintf("UPDATE artifacts SET \"name\" = '%s', description = '%s' WHERE id = '%s';", name, description, id)
+	ids := make([]string, len(artifacts))
+	names := make([]string, len(artifacts))
+	descriptions := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		ids[i] = artifact.Key
+		names[i] = artifact.Name
+		descriptions[i] = artifact.Description
 	}
 
-	_, err := db.Exec(stmt)
+	stmt := `UPDATE artifacts AS a SET "name" = u.name, description = u.description
+FROM unnest($1::uuid[], $2::text[], $3::text[]) AS u(id, name, description)
+WHERE a.id = u.id;`
+
+	result, err := db.Exec(stmt, pq.Array(ids), pq.Array(names), pq.Array(descriptions))
 	if err != nil {
 		return 0, errors.Wrap(err, "failed updating table")
 	}
 
-	var counter int
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&counter)
+	count, err := result.RowsAffected()
 	if err != nil {
-		return 0, errors.Wrap(err, "failed counting rows")
+		return 0, errors.Wrap(err, "failed counting updated rows")
 	}
 
-	return counter, nil
+	return int(count), nil
 }
 
-func queryReadPostgresArtifacts(db *sql.DB, ids []string) error {
+func updateBulkPostgresArtifactsInlined(db *sql.DB, artifacts []Artifact) (int, error) {
 
-	var stmt string
+	var stmt strings.Builder
 
-	for _, id := range ids {
-		stmt = stmt + fmt.Sprintf("SELECT name FROM artifacts WHERE id = '%s';", id)
+	for _, artifact := range artifacts {
+		stmt.WriteString(fmt.Sprintf("UPDATE artifacts SET \"name\" = '%s', description = '%s' WHERE id = '%s';", artifact.Name, artifact.Description, artifact.Key))
 	}
 
-	rows, err := db.Query(stmt)
+	_, err := db.Exec(stmt.String())
 	if err != nil {
-		return errors.Wrap(err, "failed reading table")
+		return 0, errors.Wrap(err, "failed updating table")
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var name string
+	// NOTE: The driver reports affected rows of the last statement only.
 
-		err = rows.Scan(&name)
+	return len(artifacts), nil
+}
+
+// queryReadPostgresArtifacts reads the rows one by one by a prepared statement, the same SELECT per row as the
+// inlined variant but with the ID bound. Unlike the bulk read it does one lookup per key, lib/pq can not send
+// bound statements in one request, so every row is a round trip.
+func queryReadPostgresArtifacts(db *sql.DB, ids []string) (int, error) {
+
+	stmt, err := db.Prepare("SELECT name FROM artifacts WHERE id = $1;")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed preparing statement")
+	}
+	defer stmt.Close()
+
+	var count int
+	for _, id := range ids {
+		var name string
+		err := stmt.QueryRow(id).Scan(&name)
+		if err == sql.ErrNoRows {
+			continue
+		}
 		if err != nil {
-			return errors.Wrap(err, "failed scanning variables")
+			return 0, errors.Wrap(err, "failed reading table")
 		}
+		count += 1
 	}
 
-	return nil
+	return count, nil
 }
 
-func createPostgresConnectedPairs(db *sql.DB, n int) ([]string, []string, int, int, error) {
+// queryReadPostgresArtifactsInlined sends one SELECT per row with the ID formatted into the statement, all in
+// one request.
+func queryReadPostgresArtifactsInlined(db *sql.DB, ids []string) (int, error) {
 
 	var stmt string
-	var artifactIDs []string
-	var edgeIDs []string
 
-	tm := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range ids {
+		stmt = stmt + fmt.Sprintf("SELECT name FROM artifacts WHERE id = '%s';", id)
+	}
+
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
 
-	for i := 0; i < n; i++ {
-		edgeID, _ := uuid.NewUUID()
-		fromID, _ := uuid.NewUUID()
-		toID, _ := uuid.NewUUID()
-		fromName := fmt.Sprintf("name-from-%d", i)
-		toName := fmt.Sprintf("name-to-%d", i)
-		fromDesc := fmt.Sprintf("description-%d", i)
-		toDesc := fmt.Sprintf("description-%d", i)
-		body := fmt.Sprintf("body-%d", i)
+	// Every statement returns its own result set.
 
-		stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description, create_time) VALUES ('%s', '%s', '%s', '%s');", fromID, fromName, fromDesc, tm.Format(time.RFC3339))
-		stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description, create_time) VALUES ('%s', '%s', '%s', '%s');", toID, toName, toDesc, tm.Format(time.RFC3339))
-		stmt += fmt.Sprintf("INSERT INTO edges(id, \"from\", \"to\", body) VALUES ('%s', '%s', '%s', '%s');", edgeID, fromID, toID, body)
+	var count int
+	for {
+		for rows.Next() {
+			var name string
+
+			err = rows.Scan(&name)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed scanning variables")
+			}
+			count += 1
+		}
 
-		artifactIDs = append(artifactIDs, fromID.String(), toID.String())
-		edgeIDs = append(edgeIDs, edgeID.String())
-		tm = tm.AddDate(0, 0, 1)
+		if !rows.NextResultSet() {
+			break
+		}
 	}
 
-	_, err := db.Exec(stmt)
+	return count, nil
+}
+
+// createPostgresGraph creates the artifacts and the edges connecting them by the bulk strategy in one
+// transaction. It is used for bulk creation, pairs, chains and neighbours.
+func createPostgresGraph(db *sql.DB, dataset Dataset, bulk PostgresBulk) error {
+
+	tx, err := db.Begin()
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed inserting into table")
+		return errors.Wrap(err, "failed creating transaction")
 	}
 
-	var artifactCounter int
-	var edgeCounter int
-
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
+	err = bulk.insert(tx, postgresArtifactTable, postgresArtifactRows(dataset.Artifacts))
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
+		_ = tx.Rollback()
+		return errors.Wrap(err, "failed inserting into table")
 	}
 
-	err = db.QueryRow("SELECT COUNT(*) FROM edges;").Scan(&edgeCounter)
+	err = bulk.insert(tx, postgresEdgeTable, postgresEdgeRows(dataset.Edges))
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in edge table")
+		_ = tx.Rollback()
+		return errors.Wrap(err, "failed inserting into table")
 	}
 
-	return artifactIDs, edgeIDs, artifactCounter, edgeCounter, nil
-}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
 
-func queryAllPostgresPairs(db *sql.DB) (int, error) {
+	return nil
+}
 
-	// NOTE: Controversial comparing to Arango.
+// createPostgresGraphInlined creates the artifacts and the edges connecting them in one request of inlined
+// statements.
+func createPostgresGraphInlined(db *sql.DB, dataset Dataset) error {
 
-	stmt := "SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id;"
+	var stmt strings.Builder
 
-	rows, err := db.Query(stmt)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed reading table")
+	for _, artifact := range dataset.Artifacts {
+		stmt.WriteString(insertPostgresArtifactStmt(artifact))
 	}
-	defer rows.Close()
 
-	var count int
-	for rows.Next() {
-		var name string
+	for _, edge := range dataset.Edges {
+		stmt.WriteString(insertPostgresEdgeStmt(edge))
+	}
 
-		err = rows.Scan(&name)
-		if err != nil {
-			return 0, errors.Wrap(err, "failed scanning variables")
-		}
-		count += 1
+	_, err := db.Exec(stmt.String())
+	if err != nil {
+		return errors.Wrap(err, "failed inserting into table")
 	}
 
-	return count, nil
+	return nil
 }
 
-func queryAllPostgresPairsOneYear(db *sql.DB, year int) (int, error) {
-
-	// NOTE: Controversial comparing to Arango.
-
-	stmt := fmt.Sprintf("SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id WHERE date_part('year', t.create_time) = %d;", year)
+// countPostgresQueryRows runs the query and counts the returned rows.
+func countPostgresQueryRows(db *sql.DB, stmt string, args ...interface{}) (int, error) {
 
-	rows, err := db.Query(stmt)
+	rows, err := db.Query(stmt, args...)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed reading table")
 	}
@@ -315,75 +386,77 @@ func queryAllPostgresPairsOneYear(db *sql.DB, year int) (int, error) {
 		count += 1
 	}
 
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed reading rows")
+	}
+
 	return count, nil
 }
 
-func createPostgresChain(db *sql.DB, n int) ([]string, []string, int, int, error) {
+func queryAllPostgresPairs(db *sql.DB) (int, error) {
 
-	var stmt string
+	// NOTE: Controversial comparing to Arango.
 
-	lastID, _ := uuid.NewUUID()
-	stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', 'name-0', 'description-0');", lastID)
+	stmt := "SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id;"
 
-	artifactIDs := []string{lastID.String()}
-	var edgeIDs []string
+	return countPostgresQueryRows(db, stmt)
+}
 
-	for i := 0; i < n-1; i++ {
-		edgeID, _ := uuid.NewUUID()
-		artifactID, _ := uuid.NewUUID()
-		name := fmt.Sprintf("name-%d", i+1)
-		desc := fmt.Sprintf("description-%d", i+1)
-		body := fmt.Sprintf("body-%d", i)
+func queryAllPostgresPairsOneYear(db *sql.DB, year int) (int, error) {
 
-		stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', '%s', '%s');", artifactID, name, desc)
-		stmt += fmt.Sprintf("INSERT INTO edges(id, \"from\", \"to\", body) VALUES ('%s', '%s', '%s', '%s');", edgeID, lastID, artifactID, body)
+	// NOTE: Controversial comparing to Arango.
 
-		artifactIDs = append(artifactIDs, artifactID.String())
-		edgeIDs = append(edgeIDs, edgeID.String())
+	stmt := "SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id WHERE date_part('year', t.create_time) = $1;"
 
-		lastID = artifactID
-	}
+	return countPostgresQueryRows(db, stmt, year)
+}
 
-	_, err := db.Exec(stmt)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed inserting into table")
-	}
+func queryAllPostgresPairsOneYearInlined(db *sql.DB, year int) (int, error) {
 
-	var artifactCounter int
-	var edgeCounter int
+	stmt := fmt.Sprintf("SELECT t.name FROM edges INNER JOIN artifacts f ON edges.from = f.id INNER JOIN artifacts t ON edges.to = t.id WHERE date_part('year', t.create_time) = %d;", year)
 
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
-	}
+	return countPostgresQueryRows(db, stmt)
+}
 
-	err = db.QueryRow("SELECT COUNT(*) FROM edges;").Scan(&edgeCounter)
-	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in edge table")
-	}
+const postgresNeighbourNStmt = `
+WITH RECURSIVE neighbours(id, name, n) as (
+    SELECT id, name, 0 FROM artifacts WHERE id = %s
+UNION
+    SELECT e.to, a.name, n+1 FROM edges e INNER JOIN neighbours n ON e.from = n.id INNER JOIN artifacts a ON e.to = a.id WHERE n < %s
+) SELECT * FROM neighbours LIMIT 1 OFFSET %s;
+`
 
-	return artifactIDs, edgeIDs, artifactCounter, edgeCounter, nil
-}
+const postgresSumNeighbourNStmt = `
+WITH RECURSIVE neighbours(id, name, item, n) as (
+    SELECT id, name, item, 0 FROM artifacts WHERE id = %s
+UNION
+    SELECT e.to, a.name, a.item, n+1 FROM edges e INNER JOIN neighbours n ON e.from = n.id INNER JOIN artifacts a ON e.to = a.id WHERE n < %s
+) SELECT sum(item) FROM neighbours;
+`
 
 func queryPostgresNeighbourN(db *sql.DB, startingID string, i int) (string, string, error) {
 
 	// NOTE: Controversial comparing to Arango.
 
-	stmt := `
-WITH RECURSIVE neighbours(id, name, n) as (
-    SELECT id, name, 0 FROM artifacts WHERE id = '%s'
-UNION
-    SELECT e.to, a.name, n+1 FROM edges e INNER JOIN neighbours n ON e.from = n.id INNER JOIN artifacts a ON e.to = a.id WHERE n < %d
-) SELECT * FROM neighbours LIMIT 1 OFFSET %d;
-`
+	stmt := fmt.Sprintf(postgresNeighbourNStmt, "$1", "$2", "$2")
+
+	return scanPostgresNeighbour(db.QueryRow(stmt, startingID, i))
+}
+
+func queryPostgresNeighbourNInlined(db *sql.DB, startingID string, i int) (string, string, error) {
+
+	stmt := fmt.Sprintf(postgresNeighbourNStmt, "'"+startingID+"'", fmt.Sprint(i), fmt.Sprint(i))
+
+	return scanPostgresNeighbour(db.QueryRow(stmt))
+}
 
-	stmt = fmt.Sprintf(stmt, startingID, i, i)
+func scanPostgresNeighbour(row *sql.Row) (string, string, error) {
 
 	var id string
 	var name string
 	var n int
 
-	err := db.QueryRow(stmt).Scan(&id, &name, &n)
+	err := row.Scan(&id, &name, &n)
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed searching in chain")
 	}
@@ -395,19 +468,23 @@ func sumPostgresNeighbourNItems(db *sql.DB, startingID string, i int) (int, erro
 
 	// NOTE: Controversial comparing to Arango.
 
-	stmt := `
-WITH RECURSIVE neighbours(id, name, item, n) as (
-    SELECT id, name, item, 0 FROM artifacts WHERE id = '%s'
-UNION
-    SELECT e.to, a.name, a.item, n+1 FROM edges e INNER JOIN neighbours n ON e.from = n.id INNER JOIN artifacts a ON e.to = a.id WHERE n < %d
-) SELECT sum(item) FROM neighbours;
-`
+	stmt := fmt.Sprintf(postgresSumNeighbourNStmt, "$1", "$2")
+
+	return scanPostgresSum(db.QueryRow(stmt, startingID, i))
+}
+
+func sumPostgresNeighbourNItemsInlined(db *sql.DB, startingID string, i int) (int, error) {
+
+	stmt := fmt.Sprintf(postgresSumNeighbourNStmt, "'"+startingID+"'", fmt.Sprint(i))
 
-	stmt = fmt.Sprintf(stmt, startingID, i)
+	return scanPostgresSum(db.QueryRow(stmt))
+}
+
+func scanPostgresSum(row *sql.Row) (int, error) {
 
 	var sum int
 
-	err := db.QueryRow(stmt).Scan(&sum)
+	err := row.Scan(&sum)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed searching in chain")
 	}
@@ -415,73 +492,245 @@ UNION
 	return sum, nil
 }
 
-func createPostgresNeighbours(db *sql.DB, n int) ([]string, []string, int, int, error) {
+func queryPostgresSortedNeighbours(db *sql.DB, id string) (int, error) {
 
-	var stmt string
+	// NOTE: Controversial comparing to Arango.
 
-	firstID, _ := uuid.NewUUID()
-	stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', 'name-0', 'description-0');", firstID)
+	stmt := "SELECT a.name FROM edges e INNER JOIN artifacts a ON e.to = a.id WHERE e.from = $1 GROUP BY a.name;"
 
-	artifactIDs := []string{firstID.String()}
-	var edgeIDs []string
+	return countPostgresQueryRows(db, stmt, id)
+}
 
-	for i := 0; i < n-1; i++ {
-		edgeID, _ := uuid.NewUUID()
-		artifactID, _ := uuid.NewUUID()
-		name := fmt.Sprintf("name-%d", i+1)
-		desc := fmt.Sprintf("description-%d", i+1)
-		body := fmt.Sprintf("body-%d", i)
+func queryPostgresSortedNeighboursInlined(db *sql.DB, id string) (int, error) {
 
-		stmt += fmt.Sprintf("INSERT INTO artifacts(id, \"name\", description) VALUES ('%s', '%s', '%s');", artifactID, name, desc)
-		stmt += fmt.Sprintf("INSERT INTO edges(id, \"from\", \"to\", body) VALUES ('%s', '%s', '%s', '%s');", edgeID, firstID, artifactID, body)
+	stmt := fmt.Sprintf("SELECT a.name FROM edges e INNER JOIN artifacts a ON e.to = a.id WHERE e.from = '%s' GROUP BY a.name;", id)
 
-		artifactIDs = append(artifactIDs, artifactID.String())
-		edgeIDs = append(edgeIDs, edgeID.String())
-	}
+	return countPostgresQueryRows(db, stmt)
+}
 
-	_, err := db.Exec(stmt)
+// readPostgresKeys returns the keys of all artifacts ordered by their creation time.
+func readPostgresKeys(db *sql.DB) ([]string, error) {
+
+	rows, err := db.Query(`SELECT id FROM artifacts ORDER BY create_time, id;`)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed inserting into table")
+		return nil, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed scanning variables")
+		}
+		keys = append(keys, key)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed reading rows")
+	}
+
+	return keys, nil
+}
+
+func countPostgresRows(db *sql.DB) (int, int, error) {
+
 	var artifactCounter int
 	var edgeCounter int
 
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
+	err := db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
+		return 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
 	}
 
 	err = db.QueryRow("SELECT COUNT(*) FROM edges;").Scan(&edgeCounter)
 	if err != nil {
-		return nil, nil, 0, 0, errors.Wrap(err, "failed counting rows in edge table")
+		return 0, 0, errors.Wrap(err, "failed counting rows in edge table")
 	}
 
-	return artifactIDs, edgeIDs, artifactCounter, edgeCounter, nil
+	return artifactCounter, edgeCounter, nil
 }
 
-func queryPostgresSortedNeighbours(db *sql.DB, id string) (int, error) {
+// postgresBackend adapts the PostgreSQL functions to the Backend interface. The inlined variant formats the
+// values into the statements instead of binding them, as the benchmark did originally, so both can be compared.
+// The bulk strategy is used by the bulk creation and by the creation of graphs.
+type postgresBackend struct {
+	db      *sql.DB
+	inlined bool
+	bulk    PostgresBulk
+}
 
-	// NOTE: Controversial comparing to Arango.
+// NewPostgresBackend connects to PostgreSQL and makes sure the testing tables exist. It inserts in bulk by
+// COPY.
+func NewPostgresBackend(connStr string) (Backend, error) {
+	return newPostgresBackend(connStr, false, PostgresBulkCopy)
+}
 
-	stmt := fmt.Sprintf("SELECT a.name FROM edges e INNER JOIN artifacts a ON e.to = a.id WHERE e.from = '%s' GROUP BY a.name;", id)
+// NewPostgresBulkBackend is NewPostgresBackend inserting in bulk by the strategy.
+func NewPostgresBulkBackend(connStr string, bulk PostgresBulk) (Backend, error) {
+	if !bulk.valid() {
+		return nil, errors.Errorf("unknown bulk strategy %s", bulk)
+	}
+	return newPostgresBackend(connStr, false, bulk)
+}
 
-	rows, err := db.Query(stmt)
+// NewInlinedPostgresBackend is NewPostgresBackend formatting the values into the statements.
+func NewInlinedPostgresBackend(connStr string) (Backend, error) {
+	return newPostgresBackend(connStr, true, PostgresBulkCopy)
+}
+
+func newPostgresBackend(connStr string, inlined bool, bulk PostgresBulk) (Backend, error) {
+
+	db, err := InitPostgres(connStr)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed reading table")
+		return nil, errors.Wrap(err, "failed initializing postgres")
 	}
-	defer rows.Close()
 
-	var count int
-	for rows.Next() {
-		var name string
+	if err := CreatePostgresTestingTables(db); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed creating testing tables")
+	}
 
-		err = rows.Scan(&name)
-		if err != nil {
-			return 0, errors.Wrap(err, "failed scanning variables")
+	return &postgresBackend{db: db, inlined: inlined, bulk: bulk}, nil
+}
+
+func (b *postgresBackend) Name() string {
+	switch {
+	case b.inlined:
+		return "postgres-inlined"
+	case b.bulk != PostgresBulkCopy:
+		return "postgres-" + string(b.bulk)
+	default:
+		return "postgres"
+	}
+}
+
+func (b *postgresBackend) Count(_ context.Context) (int, int, error) {
+	return countPostgresRows(b.db)
+}
+
+func (b *postgresBackend) Keys(_ context.Context) ([]string, error) {
+	return readPostgresKeys(b.db)
+}
+
+func (b *postgresBackend) Create(_ context.Context, artifacts []Artifact) error {
+	return createPostgresArtifacts(b.db, artifacts)
+}
+
+func (b *postgresBackend) BulkCreate(_ context.Context, artifacts []Artifact) error {
+	if b.inlined {
+		return createBulkPostgresArtifactsInlined(b.db, artifacts)
+	}
+	return createPostgresGraph(b.db, Dataset{Artifacts: artifacts}, b.bulk)
+}
+
+func (b *postgresBackend) Read(_ context.Context, key string) error {
+	return readOnePostgresArtifact(b.db, key)
+}
+
+func (b *postgresBackend) BulkRead(_ context.Context, keys []string) (int, error) {
+	return readBulkPostgresArtifacts(b.db, keys)
+}
+
+func (b *postgresBackend) Update(_ context.Context, artifact Artifact) error {
+	if b.inlined {
+		return updateOnePostgresArtifactInlined(b.db, artifact)
+	}
+	return updateOnePostgresArtifact(b.db, artifact)
+}
+
+func (b *postgresBackend) BulkUpdate(_ context.Context, artifacts []Artifact) (int, error) {
+	if b.inlined {
+		return updateBulkPostgresArtifactsInlined(b.db, artifacts)
+	}
+	return updateBulkPostgresArtifacts(b.db, artifacts)
+}
+
+func (b *postgresBackend) Query(_ context.Context, keys []string) (int, error) {
+	if b.inlined {
+		return queryReadPostgresArtifactsInlined(b.db, keys)
+	}
+	return queryReadPostgresArtifacts(b.db, keys)
+}
+
+func (b *postgresBackend) createGraph(dataset Dataset) error {
+	if b.inlined {
+		return createPostgresGraphInlined(b.db, dataset)
+	}
+	return createPostgresGraph(b.db, dataset, b.bulk)
+}
+
+func (b *postgresBackend) CreatePairs(_ context.Context, dataset Dataset) error {
+	return b.createGraph(dataset)
+}
+
+func (b *postgresBackend) QueryPairs(_ context.Context) (int, error) {
+	return queryAllPostgresPairs(b.db)
+}
+
+func (b *postgresBackend) QueryPairsInYear(_ context.Context, year int) (int, error) {
+	if b.inlined {
+		return queryAllPostgresPairsOneYearInlined(b.db, year)
+	}
+	return queryAllPostgresPairsOneYear(b.db, year)
+}
+
+func (b *postgresBackend) CreateChain(_ context.Context, dataset Dataset) error {
+	return b.createGraph(dataset)
+}
+
+func (b *postgresBackend) QueryNeighbourN(_ context.Context, key string, n int) (Artifact, error) {
+
+	query := queryPostgresNeighbourN
+	if b.inlined {
+		query = queryPostgresNeighbourNInlined
+	}
+
+	id, name, err := query(b.db, key, n)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{Key: id, Name: name}, nil
+}
+
+func (b *postgresBackend) SumNeighbourItems(_ context.Context, key string, n int) (int, error) {
+	if b.inlined {
+		return sumPostgresNeighbourNItemsInlined(b.db, key, n)
+	}
+	return sumPostgresNeighbourNItems(b.db, key, n)
+}
+
+func (b *postgresBackend) CreateNeighbours(_ context.Context, dataset Dataset) error {
+	return b.createGraph(dataset)
+}
+
+func (b *postgresBackend) QuerySortedNeighbours(_ context.Context, key string) (int, error) {
+	if b.inlined {
+		return queryPostgresSortedNeighboursInlined(b.db, key)
+	}
+	return queryPostgresSortedNeighbours(b.db, key)
+}
+
+func (b *postgresBackend) Cleanup(_ context.Context, graph Graph) error {
+
+	if graph.EdgeKeys != nil {
+		if err := removeBulkPostgresRows(b.db, "edges", graph.EdgeKeys); err != nil {
+			return err
 		}
-		count += 1
 	}
 
-	return count, nil
+	if graph.ArtifactKeys != nil {
+		if err := removeBulkPostgresRows(b.db, "artifacts", graph.ArtifactKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *postgresBackend) Close() error {
+	return b.db.Close()
 }
@@ -0,0 +1,207 @@
+package db_bench
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// PostgresBulk is the strategy inserting many rows into PostgreSQL at once.
+type PostgresBulk string
+
+const (
+	// PostgresBulkCopy streams the rows by `COPY FROM STDIN`.
+	PostgresBulkCopy PostgresBulk = "copy"
+
+	// PostgresBulkValues sends multi-row `INSERT ... VALUES` statements of postgresMaxRows rows.
+	PostgresBulkValues PostgresBulk = "values"
+
+	// PostgresBulkUnnest sends one `INSERT ... SELECT unnest(...)` statement binding a column array per column.
+	PostgresBulkUnnest PostgresBulk = "unnest"
+)
+
+// PostgresBulks lists all bulk strategies.
+var PostgresBulks = []PostgresBulk{PostgresBulkCopy, PostgresBulkValues, PostgresBulkUnnest}
+
+// postgresMaxRows limits the rows of one multi-row insert, PostgreSQL limits the number of bind parameters.
+const postgresMaxRows = 1000
+
+// postgresTable describes the columns filled by the bulk inserts.
+type postgresTable struct {
+	name    string
+	columns []string
+
+	// Types of the columns, the unnest strategy casts its arrays to them.
+	types []string
+}
+
+var postgresArtifactTable = postgresTable{
+	name:    "artifacts",
+	columns: []string{"id", "name", "description", "item", "create_time", "payload"},
+	types:   []string{"uuid", "text", "text", "integer", "timestamp", "jsonb"},
+}
+
+var postgresEdgeTable = postgresTable{
+	name:    "edges",
+	columns: []string{"id", "from", "to", "body"},
+	types:   []string{"uuid", "uuid", "uuid", "text"},
+}
+
+func (t postgresTable) quotedColumns() string {
+	quoted := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		quoted[i] = pq.QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func postgresArtifactRows(artifacts []Artifact) [][]interface{} {
+	rows := make([][]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
+		rows[i] = []interface{}{artifact.Key, artifact.Name, artifact.Description, artifact.Item, artifact.CreateTime, encodePayload(artifact.Payload)}
+	}
+	return rows
+}
+
+func postgresEdgeRows(edges []Edge) [][]interface{} {
+	rows := make([][]interface{}, len(edges))
+	for i, edge := range edges {
+		rows[i] = []interface{}{edge.Key, edge.From, edge.To, edge.Body}
+	}
+	return rows
+}
+
+func (b PostgresBulk) valid() bool {
+	for _, bulk := range PostgresBulks {
+		if b == bulk {
+			return true
+		}
+	}
+	return false
+}
+
+// insert inserts the rows into the table within the transaction.
+func (b PostgresBulk) insert(tx *sql.Tx, table postgresTable, rows [][]interface{}) error {
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	switch b {
+	case PostgresBulkCopy:
+		return copyPostgresRows(tx, table, rows)
+	case PostgresBulkValues:
+		return insertPostgresValues(tx, table, rows)
+	case PostgresBulkUnnest:
+		return insertPostgresUnnest(tx, table, rows)
+	default:
+		return errors.Errorf("unknown bulk strategy %s", b)
+	}
+}
+
+func copyPostgresRows(tx *sql.Tx, table postgresTable, rows [][]interface{}) error {
+
+	stmt, err := tx.Prepare(pq.CopyIn(table.name, table.columns...))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			_ = stmt.Close()
+			return err
+		}
+	}
+
+	// The rows are buffered by the driver until flushed by an empty execution.
+	if _, err := stmt.Exec(); err != nil {
+		_ = stmt.Close()
+		return err
+	}
+
+	return stmt.Close()
+}
+
+// insertPostgresValues inserts the rows by multi-row statements of postgresMaxRows rows at most.
+func insertPostgresValues(tx *sql.Tx, table postgresTable, rows [][]interface{}) error {
+
+	insert := "INSERT INTO " + table.name + "(" + table.quotedColumns() + ") VALUES "
+
+	for start := 0; start < len(rows); start += postgresMaxRows {
+		end := start + postgresMaxRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var stmt strings.Builder
+		var args []interface{}
+
+		stmt.WriteString(insert)
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			stmt.WriteString("(")
+			for j := range row {
+				if j > 0 {
+					stmt.WriteString(", ")
+				}
+				stmt.WriteString(fmt.Sprintf("$%d", len(args)+j+1))
+			}
+			stmt.WriteString(")")
+			args = append(args, row...)
+		}
+
+		if _, err := tx.Exec(stmt.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertPostgresUnnest inserts all rows by one statement. Every column is bound as a text array cast to the
+// column type, so the statement has as many parameters as the table has columns. Nil values are bound as NULL.
+func insertPostgresUnnest(tx *sql.Tx, table postgresTable, rows [][]interface{}) error {
+
+	columns := make([][]sql.NullString, len(table.columns))
+	for i := range columns {
+		columns[i] = make([]sql.NullString, len(rows))
+	}
+
+	for i, row := range rows {
+		for j, value := range row {
+			if value != nil {
+				columns[j][i] = sql.NullString{String: postgresText(value), Valid: true}
+			}
+		}
+	}
+
+	arrays := make([]string, len(table.columns))
+	args := make([]interface{}, len(table.columns))
+	for i := range table.columns {
+		arrays[i] = fmt.Sprintf("$%d::%s[]", i+1, table.types[i])
+		args[i] = pq.Array(columns[i])
+	}
+
+	stmt := "INSERT INTO " + table.name + "(" + table.quotedColumns() + ") SELECT * FROM unnest(" + strings.Join(arrays, ", ") + ");"
+
+	_, err := tx.Exec(stmt, args...)
+	return err
+}
+
+// postgresText formats the value as PostgreSQL parses it from text.
+func postgresText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
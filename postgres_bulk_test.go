@@ -0,0 +1,29 @@
+package db_bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresBulkText(t *testing.T) {
+	tm := time.Date(2022, 3, 4, 5, 6, 7, 800, time.UTC)
+	require.Equal(t, "2022-03-04T05:06:07.0000008Z", postgresText(tm))
+	require.Equal(t, "42", postgresText(42))
+	require.Equal(t, "name", postgresText("name"))
+}
+
+func TestPostgresBulkColumns(t *testing.T) {
+	require.Equal(t, `"id", "from", "to", "body"`, postgresEdgeTable.quotedColumns())
+	require.Len(t, postgresArtifactTable.types, len(postgresArtifactTable.columns))
+	require.Len(t, postgresArtifactRows([]Artifact{{}})[0], len(postgresArtifactTable.columns))
+	require.Len(t, postgresEdgeRows([]Edge{{}})[0], len(postgresEdgeTable.columns))
+}
+
+func TestPostgresBulkValid(t *testing.T) {
+	for _, bulk := range PostgresBulks {
+		require.True(t, bulk.valid())
+	}
+	require.False(t, PostgresBulk("insert").valid())
+}
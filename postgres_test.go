@@ -1,315 +1,130 @@
 package db_bench
 
 import (
+	"context"
 	"database/sql"
-	"github.com/stretchr/testify/suite"
 	"testing"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-type postgresSuite struct {
-	suite.Suite
-
-	db                      *sql.DB
-	artifactIDsToCleanNow   []string
-	artifactIDsToCleanLater []string
-	edgeIDsToCleanNow       []string
-	edgeIDsToCleanLater     []string
-	staticArtifactCount     int
-}
-
-func (s *postgresSuite) SetupSuite() {
-
-	db, err := InitPostgres(PostgresConnStr)
-	s.Require().NoError(err)
+func TestPostgresSuite(t *testing.T) {
 
-	err = CreatePostgresTestingTables(db)
-	s.Require().NoError(err)
+	config := testConfig(t)
 
-	var count int
-	err = db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&count)
-	s.Require().NoError(err)
+	backend, err := NewPostgresBackend(config.Postgres.ConnStr)
+	require.NoError(t, err)
 
-	s.db = db
-	s.staticArtifactCount = count
+	runScenarios(t, config, backend, NewMemoryBackend())
 }
 
-func (s *postgresSuite) TearDownTest() {
-	if s.edgeIDsToCleanNow != nil {
-		err := removeBulkPostgresEdges(s.db, s.edgeIDsToCleanNow)
-		s.Require().NoError(err)
+func TestPostgresInlinedSuite(t *testing.T) {
 
-		s.edgeIDsToCleanNow = nil
-	}
+	config := testConfig(t)
 
-	if s.artifactIDsToCleanNow != nil {
-		err := removeBulkPostgresArtifacts(s.db, s.artifactIDsToCleanNow)
-		s.Require().NoError(err)
+	backend, err := NewInlinedPostgresBackend(config.Postgres.ConnStr)
+	require.NoError(t, err)
 
-		s.artifactIDsToCleanNow = nil
-	}
+	runScenarios(t, config, backend, NewMemoryBackend())
 }
 
-func (s *postgresSuite) TearDownSuite() {
-	if s.edgeIDsToCleanLater != nil {
-		err := removeBulkPostgresEdges(s.db, s.edgeIDsToCleanLater)
-		s.Require().NoError(err)
+func TestPostgresBulkSuite(t *testing.T) {
 
-		s.edgeIDsToCleanLater = nil
-	}
+	config := testConfig(t)
 
-	if s.artifactIDsToCleanLater != nil {
-		err := removeBulkPostgresArtifacts(s.db, s.artifactIDsToCleanLater)
-		s.Require().NoError(err)
+	for _, bulk := range []PostgresBulk{PostgresBulkValues, PostgresBulkUnnest} {
+		t.Run(string(bulk), func(t *testing.T) {
+			backend, err := NewPostgresBulkBackend(config.Postgres.ConnStr, bulk)
+			require.NoError(t, err)
 
-		s.artifactIDsToCleanLater = nil
+			runScenarios(t, config, backend, NewMemoryBackend())
+		})
 	}
-
-	_ = s.db.Close()
-}
-
-func (s *postgresSuite) Test01_Create10() {
-
-	documentCount := 10
-
-	ids, count, err := createPostgresArtifacts(s.db, documentCount)
-	s.Require().NoError(err)
-	s.EqualValues(documentCount, count-s.staticArtifactCount)
-
-	s.artifactIDsToCleanNow = ids
 }
 
-func (s *postgresSuite) Test02_Create100() {
+func TestPostgresQueries(t *testing.T) {
 
-	documentCount := 100
+	config := testConfig(t)
 
-	ids, count, err := createPostgresArtifacts(s.db, documentCount)
-	s.Require().NoError(err)
-	s.EqualValues(documentCount, count-s.staticArtifactCount)
+	backend, err := NewPostgresBackend(config.Postgres.ConnStr)
+	require.NoError(t, err)
 
-	s.artifactIDsToCleanNow = ids
+	runQueries(t, backend)
 }
 
-func (s *postgresSuite) Test03_Create1000() {
-
-	documentCount := 1000
+// TestPostgresBulkRows writes the same chain by every bulk strategy and reads back the same rows. The chain is
+// longer than postgresMaxRows, so the values strategy splits it into two statements.
+func TestPostgresBulkRows(t *testing.T) {
 
-	ids, count, err := createPostgresArtifacts(s.db, documentCount)
-	s.Require().NoError(err)
-	s.EqualValues(documentCount, count-s.staticArtifactCount)
+	ctx := context.Background()
+	config := testConfig(t)
 
-	s.artifactIDsToCleanNow = ids
-}
+	g := NewGenerator(1)
+	g.Payload = PayloadConfig{Fields: 2, Depth: 2, Array: 4, Text: 32, Binary: 8, Distribution: string(PayloadFixed)}
+	dataset := g.Chain(postgresMaxRows + 2)
 
-func (s *postgresSuite) Test04_BulkCreate1000() {
+	var artifacts, edges [][]sql.NullString
 
-	documentCount := 1000
+	for _, bulk := range PostgresBulks {
+		t.Run(string(bulk), func(t *testing.T) {
+			backend, err := NewPostgresBulkBackend(config.Postgres.ConnStr, bulk)
+			require.NoError(t, err)
+			defer backend.Close()
 
-	ids, count, err := createBulkPostgresArtifacts(s.db, documentCount)
-	s.Require().NoError(err)
-	s.EqualValues(documentCount, count-s.staticArtifactCount)
+			require.NoError(t, backend.CreateChain(ctx, dataset))
+			defer backend.Cleanup(ctx, dataset.Keys())
 
-	s.artifactIDsToCleanNow = ids
-}
+			db := backend.(*postgresBackend).db
 
-func (s *postgresSuite) Test05_BulkCreate10000() {
+			storedArtifacts := readPostgresRows(t, db, `SELECT id::text, name, description, item::text, create_time::text, payload::text
+				FROM artifacts WHERE id = ANY($1::uuid[]) ORDER BY id`, artifactKeys(dataset.Artifacts))
+			storedEdges := readPostgresRows(t, db, `SELECT id::text, "from"::text, "to"::text, body
+				FROM edges WHERE id = ANY($1::uuid[]) ORDER BY id`, edgeKeys(dataset.Edges))
 
-	documentCount := 10000
+			require.Len(t, storedArtifacts, len(dataset.Artifacts))
+			require.Len(t, storedEdges, len(dataset.Edges))
 
-	ids, count, err := createBulkPostgresArtifacts(s.db, documentCount)
-	s.Require().NoError(err)
-	s.EqualValues(documentCount, count-s.staticArtifactCount)
+			payloads := make(map[string]string)
+			for _, row := range storedArtifacts {
+				payloads[row[0].String] = row[5].String
+			}
+			for _, artifact := range dataset.Artifacts {
+				assert.JSONEq(t, encodePayload(artifact.Payload).(string), payloads[artifact.Key])
+			}
 
-	s.artifactIDsToCleanLater = ids
-}
-
-func (s *postgresSuite) Test06_Read10000() {
-	s.T().Skip("no similar action in API (to Arango)")
-}
-
-func (s *postgresSuite) Test07_BulkRead10000() {
-	s.T().Skip("no similar action in API (to Arango)")
-}
-
-func (s *postgresSuite) Test08_Update10000() {
-	for _, id := range s.artifactIDsToCleanLater {
-		err := updateOnePostgresArtifact(s.db, id)
-		s.Require().NoError(err)
+			if artifacts == nil {
+				artifacts, edges = storedArtifacts, storedEdges
+				return
+			}
+			assert.Equal(t, artifacts, storedArtifacts)
+			assert.Equal(t, edges, storedEdges)
+		})
 	}
 }
 
-func (s *postgresSuite) Test09_BulkUpdate10000() {
-	count, err := updateBulkPostgresArtifacts(s.db, s.artifactIDsToCleanLater)
-	s.Require().NoError(err)
-	s.EqualValues(len(s.artifactIDsToCleanLater), count-s.staticArtifactCount)
-}
-
-func (s *postgresSuite) Test10_QueryRead10000() {
-	err := queryReadPostgresArtifacts(s.db, s.artifactIDsToCleanLater)
-	s.Require().NoError(err)
-
-	s.artifactIDsToCleanNow = s.artifactIDsToCleanLater
-	s.artifactIDsToCleanLater = nil
-}
-
-func (s *postgresSuite) Test11_CreateConnectedPairs10() {
-
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresConnectedPairs(s.db, 10)
-	s.Require().NoError(err)
-	s.EqualValues(20, artifactCount-s.staticArtifactCount)
-	s.EqualValues(10, edgeCount)
-
-	s.artifactIDsToCleanNow = artifactIDs
-	s.edgeIDsToCleanNow = edgeIDs
-}
-
-func (s *postgresSuite) Test12_CreateConnectedPairs100() {
-
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresConnectedPairs(s.db, 100)
-	s.Require().NoError(err)
-	s.EqualValues(200, artifactCount-s.staticArtifactCount)
-	s.EqualValues(100, edgeCount)
-
-	s.artifactIDsToCleanNow = artifactIDs
-	s.edgeIDsToCleanNow = edgeIDs
-}
-
-func (s *postgresSuite) Test13_CreateConnectedPairs10000() {
-
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresConnectedPairs(s.db, 10000)
-	s.Require().NoError(err)
-	s.EqualValues(20000, artifactCount-s.staticArtifactCount)
-	s.EqualValues(10000, edgeCount)
-
-	s.artifactIDsToCleanLater = artifactIDs
-	s.edgeIDsToCleanLater = edgeIDs
-}
-
-func (s *postgresSuite) Test14_QueryAllConnectedPairs10000() {
-
-	count, err := queryAllPostgresPairs(s.db)
-	s.Require().NoError(err)
-	s.EqualValues(10000, count)
-}
-
-func (s *postgresSuite) Test15_QueryAllConnectedPairsOneYear10000() {
-
-	count, err := queryAllPostgresPairsOneYear(s.db, 2022)
-	s.Require().NoError(err)
-	s.EqualValues(365, count)
-
-	s.edgeIDsToCleanNow = s.edgeIDsToCleanLater
-	s.artifactIDsToCleanNow = s.artifactIDsToCleanLater
-	s.edgeIDsToCleanLater = nil
-	s.artifactIDsToCleanLater = nil
-}
-
-func (s *postgresSuite) Test16_CreateChain1x10000() {
-
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresChain(s.db, 10000)
-	s.Require().NoError(err)
-	s.EqualValues(10000, artifactCount-s.staticArtifactCount)
-	s.EqualValues(9999, edgeCount)
-
-	s.artifactIDsToCleanLater = artifactIDs
-	s.edgeIDsToCleanLater = edgeIDs
-}
-
-func (s *postgresSuite) Test17_QueryNeighbourInChain10() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 10)
-	s.Require().NoError(err)
-	s.EqualValues("name-10", name)
-	s.EqualValues(s.artifactIDsToCleanLater[10], id)
-}
-
-func (s *postgresSuite) Test18_QueryNeighbourInChain100() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 100)
-	s.Require().NoError(err)
-	s.EqualValues("name-100", name)
-	s.EqualValues(s.artifactIDsToCleanLater[100], id)
-}
-
-func (s *postgresSuite) Test19_QueryNeighbourInChain1000() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 1000)
-	s.Require().NoError(err)
-	s.EqualValues("name-1000", name)
-	s.EqualValues(s.artifactIDsToCleanLater[1000], id)
-}
+// readPostgresRows returns the text of all columns of the rows selected by the keys.
+func readPostgresRows(t *testing.T, db *sql.DB, query string, keys []string) [][]sql.NullString {
 
-func (s *postgresSuite) Test20_QueryNeighbourInChain2000() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 2000)
-	s.Require().NoError(err)
-	s.EqualValues("name-2000", name)
-	s.EqualValues(s.artifactIDsToCleanLater[2000], id)
-}
+	rows, err := db.Query(query, pq.Array(keys))
+	require.NoError(t, err)
+	defer rows.Close()
 
-func (s *postgresSuite) Test21_QueryNeighbourInChain5000() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 5000)
-	s.Require().NoError(err)
-	s.EqualValues("name-5000", name)
-	s.EqualValues(s.artifactIDsToCleanLater[5000], id)
-}
+	columns, err := rows.Columns()
+	require.NoError(t, err)
 
-func (s *postgresSuite) Test22_QueryNeighbourInChain7000() {
-	id, name, err := queryPostgresNeighbourN(s.db, s.artifactIDsToCleanLater[0], 7000)
-	s.Require().NoError(err)
-	s.EqualValues("name-7000", name)
-	s.EqualValues(s.artifactIDsToCleanLater[7000], id)
-}
-
-func (s *postgresSuite) Test23_SumChainItems5000() {
-
-	sum, err := sumPostgresNeighbourNItems(s.db, s.artifactIDsToCleanLater[0], 4999)
-	s.Require().NoError(err)
-	s.EqualValues(5000, sum)
-
-	s.edgeIDsToCleanNow = s.edgeIDsToCleanLater
-	s.artifactIDsToCleanNow = s.artifactIDsToCleanLater
-	s.edgeIDsToCleanLater = nil
-	s.artifactIDsToCleanLater = nil
-}
-
-func (s *postgresSuite) Test24_CreateNeighbours100() {
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresNeighbours(s.db, 100)
-	s.Require().NoError(err)
-	s.EqualValues(100, artifactCount-s.staticArtifactCount)
-	s.EqualValues(99, edgeCount)
-
-	s.artifactIDsToCleanNow = artifactIDs
-	s.edgeIDsToCleanNow = edgeIDs
-}
-
-func (s *postgresSuite) Test25_CreateNeighbours1000() {
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresNeighbours(s.db, 1000)
-	s.Require().NoError(err)
-	s.EqualValues(1000, artifactCount-s.staticArtifactCount)
-	s.EqualValues(999, edgeCount)
-
-	s.artifactIDsToCleanNow = artifactIDs
-	s.edgeIDsToCleanNow = edgeIDs
-}
-
-func (s *postgresSuite) Test26_CreateNeighbours10000() {
-	artifactIDs, edgeIDs, artifactCount, edgeCount, err := createPostgresNeighbours(s.db, 10000)
-	s.Require().NoError(err)
-	s.EqualValues(10000, artifactCount-s.staticArtifactCount)
-	s.EqualValues(9999, edgeCount)
-
-	s.artifactIDsToCleanLater = artifactIDs
-	s.edgeIDsToCleanLater = edgeIDs
-}
-
-func (s *postgresSuite) Test27_QueryArangoSortedNeighbours10000() {
-	count, err := queryPostgresSortedNeighbours(s.db, s.artifactIDsToCleanLater[0])
-	s.Require().NoError(err)
-	s.EqualValues(9999, count)
-}
-
-func (s *postgresSuite) HandleStats(suiteName string, stats *suite.SuiteInformation) {
-	printStats(s.T(), suiteName, stats)
-}
+	var result [][]sql.NullString
+	for rows.Next() {
+		row := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		require.NoError(t, rows.Scan(dest...))
+		result = append(result, row)
+	}
+	require.NoError(t, rows.Err())
 
-func TestPostgresSuite(t *testing.T) {
-	suite.Run(t, new(postgresSuite))
+	return result
 }
@@ -0,0 +1,292 @@
+package db_bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Status describes how a scenario run ended.
+type Status string
+
+const (
+	StatusOK             Status = "ok"
+	StatusSkipped        Status = "skipped"
+	StatusNotImplemented Status = "not-implemented"
+	StatusFailed         Status = "failed"
+)
+
+// Result is the outcome of one scenario run against one backend.
+type Result struct {
+	Backend  string `json:"backend"`
+	Num      int    `json:"num"`
+	Scenario string `json:"scenario"`
+	Title    string `json:"title"`
+	Params   Params `json:"params,omitempty"`
+	Depends  string `json:"depends,omitempty"`
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+
+	// Number of artifacts and edges stored before the first scenario.
+	StaticArtifactCount int `json:"static_artifact_count"`
+	StaticEdgeCount     int `json:"static_edge_count"`
+
+	// Durations of the measured phase of all iterations and their statistics.
+	Iterations []time.Duration `json:"iterations"`
+	Stats      Stats           `json:"stats"`
+
+	// Statistics of the phases around the measured one.
+	Prepare Stats `json:"prepare"`
+	Verify  Stats `json:"verify"`
+
+	// Concurrent runs only: number of workers and operations of all iterations, operations per second of the
+	// measured phase and latencies of the operations.
+	Workers    int     `json:"workers,omitempty"`
+	Operations int     `json:"operations,omitempty"`
+	Throughput float64 `json:"throughput,omitempty"`
+	Latency    Stats   `json:"latency"`
+
+	// Open-loop runs only: target rate (operations per second), statistics of the service times (latencies
+	// without waiting for a worker) and the histogram of the latencies.
+	Rate      float64           `json:"rate,omitempty"`
+	Service   Stats             `json:"service"`
+	Histogram []HistogramBucket `json:"histogram,omitempty"`
+}
+
+// NewResult creates the result of a scenario run from the timings of its iterations and error.
+func NewResult(backend string, scenario Scenario, timings []Timing, err error) Result {
+
+	iterations := make([]time.Duration, len(timings))
+	prepare := make([]time.Duration, len(timings))
+	verify := make([]time.Duration, len(timings))
+	var latencies []time.Duration
+	var measured time.Duration
+	var workers int
+	var rate float64
+	var latency, service *Histogram
+	for i, timing := range timings {
+		iterations[i] = timing.Measured
+		prepare[i] = timing.Prepare
+		verify[i] = timing.Verify
+		latencies = append(latencies, timing.Operations...)
+		measured += timing.Measured
+		workers = timing.Workers
+		if timing.Latency != nil {
+			if latency == nil {
+				latency, service = NewHistogram(), NewHistogram()
+			}
+			latency.Merge(timing.Latency)
+			service.Merge(timing.Service)
+			rate = timing.Rate
+		}
+	}
+
+	result := Result{
+		Backend:    backend,
+		Num:        scenario.Num,
+		Scenario:   scenario.Name,
+		Title:      scenario.Title,
+		Params:     scenario.Params,
+		Depends:    scenario.Depends,
+		Status:     StatusOK,
+		Iterations: iterations,
+		Stats:      ComputeStats(iterations),
+		Prepare:    ComputeStats(prepare),
+		Verify:     ComputeStats(verify),
+	}
+
+	if workers > 0 {
+		result.Workers = workers
+		result.Operations = len(latencies)
+		result.Latency = ComputeStats(latencies)
+	}
+
+	if latency != nil {
+		result.Rate = rate
+		result.Operations = int(latency.Count())
+		result.Latency = latency.Stats()
+		result.Service = service.Stats()
+		result.Histogram = latency.Buckets()
+	}
+
+	if result.Operations > 0 && measured > 0 {
+		result.Throughput = float64(result.Operations) / measured.Seconds()
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotImplemented):
+		result.Status = StatusNotImplemented
+		result.Error = err.Error()
+	case errors.Is(err, ErrSkipped):
+		result.Status = StatusSkipped
+		result.Error = err.Error()
+	default:
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Saturated tells whether the backend could not keep up with the target rate of an open-loop run: it completed
+// less than 90 % of the target throughput.
+func (r Result) Saturated() bool {
+	return r.Rate > 0 && r.Throughput < 0.9*r.Rate
+}
+
+// ID returns the scenario name prefixed with its number, e.g. `05_BulkCreate10000`.
+func (r Result) ID() string {
+	return Scenario{Num: r.Num, Name: r.Scenario}.ID()
+}
+
+// Metadata describes the runner invocation and the machine it ran on.
+type Metadata struct {
+	Started    time.Time `json:"started"`
+	Finished   time.Time `json:"finished"`
+	Hostname   string    `json:"hostname"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	CPUs       int       `json:"cpus"`
+	GoVersion  string    `json:"go_version"`
+	Args       []string  `json:"args"`
+	Warmup     int       `json:"warmup"`
+	Iterations int       `json:"iterations"`
+	Workers    int       `json:"workers,omitempty"`
+	Rates      []float64 `json:"rates,omitempty"`
+	Seed       int       `json:"seed"`
+
+	// Payload of the generated artifacts, the same scenarios are compared at several document sizes.
+	Payload PayloadConfig `json:"payload"`
+
+	// Access pattern of the read and update scenarios.
+	Access AccessConfig `json:"access"`
+
+	// BackendErrors holds the error of every backend failing to open, run or clean up, by backend name. The
+	// results of its scenarios measured before the failure are kept.
+	BackendErrors map[string]string `json:"backend_errors,omitempty"`
+}
+
+// NewMetadata describes the current process started now.
+func NewMetadata(warmup, iterations int) Metadata {
+
+	hostname, _ := os.Hostname()
+
+	return Metadata{
+		Started:    time.Now(),
+		Hostname:   hostname,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+		GoVersion:  runtime.Version(),
+		Args:       os.Args,
+		Warmup:     warmup,
+		Iterations: iterations,
+	}
+}
+
+// Report holds all results of one runner invocation.
+type Report struct {
+	Metadata Metadata `json:"metadata"`
+	Results  []Result `json:"results"`
+}
+
+// WriteReport writes the report as an indented JSON document.
+func WriteReport(w io.Writer, report Report) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		return errors.Wrap(err, "failed encoding report")
+	}
+
+	return nil
+}
+
+// ReadReport reads a report written by WriteReport.
+func ReadReport(r io.Reader) (Report, error) {
+
+	var report Report
+
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return Report{}, errors.Wrap(err, "failed decoding report")
+	}
+
+	return report, nil
+}
+
+var csvHeader = []string{
+	"backend", "num", "scenario", "title", "params", "status", "error", "static_artifact_count", "static_edge_count",
+	"count", "min_ms", "mean_ms", "median_ms", "p90_ms", "p99_ms", "max_ms", "stddev_ms", "prepare_median_ms",
+	"verify_median_ms", "iterations_ms", "workers", "operations", "throughput_ops", "latency_median_ms",
+	"latency_p90_ms", "latency_p99_ms", "rate", "service_median_ms", "service_p99_ms",
+}
+
+// WriteCSV writes one line per result. The iterations are joined by a semicolon.
+func WriteCSV(w io.Writer, report Report) error {
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return errors.Wrap(err, "failed writing csv header")
+	}
+
+	for _, result := range report.Results {
+		iterations := make([]string, len(result.Iterations))
+		for i, iteration := range result.Iterations {
+			iterations[i] = formatMilliseconds(iteration)
+		}
+
+		record := []string{
+			result.Backend,
+			strconv.Itoa(result.Num),
+			result.Scenario,
+			result.Title,
+			result.Params.String(),
+			string(result.Status),
+			result.Error,
+			strconv.Itoa(result.StaticArtifactCount),
+			strconv.Itoa(result.StaticEdgeCount),
+			strconv.Itoa(result.Stats.Count),
+			formatMilliseconds(result.Stats.Min),
+			formatMilliseconds(result.Stats.Mean),
+			formatMilliseconds(result.Stats.Median),
+			formatMilliseconds(result.Stats.P90),
+			formatMilliseconds(result.Stats.P99),
+			formatMilliseconds(result.Stats.Max),
+			formatMilliseconds(result.Stats.StdDev),
+			formatMilliseconds(result.Prepare.Median),
+			formatMilliseconds(result.Verify.Median),
+			strings.Join(iterations, ";"),
+			strconv.Itoa(result.Workers),
+			strconv.Itoa(result.Operations),
+			strconv.FormatFloat(result.Throughput, 'f', 1, 64),
+			formatMilliseconds(result.Latency.Median),
+			formatMilliseconds(result.Latency.P90),
+			formatMilliseconds(result.Latency.P99),
+			strconv.FormatFloat(result.Rate, 'f', -1, 64),
+			formatMilliseconds(result.Service.Median),
+			formatMilliseconds(result.Service.P99),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return errors.Wrap(err, "failed writing csv record")
+		}
+	}
+
+	writer.Flush()
+
+	return errors.Wrap(writer.Error(), "failed flushing csv")
+}
+
+func formatMilliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
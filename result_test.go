@@ -0,0 +1,78 @@
+package db_bench
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResultStatus(t *testing.T) {
+
+	scenario, ok := FindScenario("BulkCreate10000")
+	require.True(t, ok)
+
+	assert.Equal(t, StatusOK, NewResult("test", scenario, nil, nil).Status)
+	assert.Equal(t, StatusNotImplemented, NewResult("test", scenario, nil, errors.Wrap(ErrNotImplemented, "x")).Status)
+	assert.Equal(t, StatusSkipped, NewResult("test", scenario, nil, errors.Wrap(ErrSkipped, "x")).Status)
+	assert.Equal(t, StatusFailed, NewResult("test", scenario, nil, errors.New("x")).Status)
+}
+
+func TestReportRoundTrip(t *testing.T) {
+
+	scenario, ok := FindScenario("5")
+	require.True(t, ok)
+
+	timings := []Timing{
+		{Prepare: time.Millisecond, Measured: 3 * time.Millisecond, Verify: time.Millisecond},
+		{Prepare: time.Millisecond, Measured: 5 * time.Millisecond, Verify: time.Millisecond},
+	}
+
+	report := Report{
+		Metadata: NewMetadata(1, 2),
+		Results:  []Result{NewResult("test", scenario, timings, nil)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, report))
+
+	read, err := ReadReport(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, report.Metadata.Iterations, read.Metadata.Iterations)
+	assert.Equal(t, report.Results[0].Iterations, read.Results[0].Iterations)
+	assert.Equal(t, 4*time.Millisecond, read.Results[0].Stats.Median)
+	assert.Equal(t, Params{"n": 10000}, read.Results[0].Params)
+}
+
+func TestWriteCSV(t *testing.T) {
+
+	scenario, ok := FindScenario("05_BulkCreate10000")
+	require.True(t, ok)
+
+	report := Report{
+		Results: []Result{NewResult("test", scenario, []Timing{{Measured: 1500 * time.Microsecond}}, nil)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, report))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+
+	assert.Equal(t, "test", row["backend"])
+	assert.Equal(t, "BulkCreate10000", row["scenario"])
+	assert.Equal(t, "n=10000", row["params"])
+	assert.Equal(t, "1.500", row["median_ms"])
+	assert.Equal(t, "1.500", row["iterations_ms"])
+}
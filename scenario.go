@@ -0,0 +1,838 @@
+package db_bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	documentCountNotToCycle = 1000000
+)
+
+// ErrSkipped is returned by a scenario which can not run under the current conditions.
+var ErrSkipped = errors.New("skipped")
+
+// Scenario is one measured use-case. Scenarios are backend independent, they drive the Backend interface.
+type Scenario struct {
+
+	// Num orders the scenarios, it is also shown in reports.
+	Num int
+
+	// Name is a short identifier of the scenario, e.g. `BulkCreate10000`.
+	Name string
+
+	// Title is a human readable description of the scenario.
+	Title string
+
+	// Params describes the scenario size, e.g. the number of created entries.
+	Params Params
+
+	// Depends names the scenario which creates the data this scenario works on. Dependent scenarios have to
+	// run right after it.
+	Depends string
+
+	// Prepare generates the data of one run and returns its measured and verify phases. It gets the Params.
+	Prepare PrepareFunc
+}
+
+// Params are named numeric parameters of a scenario.
+type Params map[string]int
+
+// String formats the parameters as `name=value` pairs sorted by name.
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%d", name, p[name])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// PrepareFunc is the prepare phase of a scenario. Everything that is not a database work, e.g. data
+// generation, belongs here.
+type PrepareFunc func(ctx context.Context, b *Bench, params Params) (Execution, error)
+
+// Execution holds the phases of a prepared scenario run.
+type Execution struct {
+
+	// Measured does the database work only. It is the only timed phase reported as the scenario duration.
+	Measured func(ctx context.Context) error
+
+	// Ops is the measured phase split into operations for the concurrent workers, it is optional. Scenarios
+	// without it run sequentially even if there are workers.
+	Ops *Ops
+
+	// Verify checks the outcome of the measured phase, it is optional.
+	Verify func(ctx context.Context) error
+
+	// Check replays the measured phase on the oracle and compares the answers, it is optional. Writing
+	// scenarios replay their writes, so the oracle holds the same data as the backend.
+	Check func(ctx context.Context, oracle Backend) error
+}
+
+// Timing holds the durations of the phases of one scenario run.
+type Timing struct {
+	Prepare  time.Duration
+	Measured time.Duration
+	Verify   time.Duration
+
+	// Number of workers and latencies of the operations of a concurrent run.
+	Workers    int
+	Operations []time.Duration
+
+	// Target rate of an open-loop run, the histograms of its operation latencies (measured from the intended
+	// start) and service times (measured from the actual start).
+	Rate    float64
+	Latency *Histogram
+	Service *Histogram
+}
+
+// ID returns the scenario name prefixed with its number, e.g. `05_BulkCreate10000`.
+func (s Scenario) ID() string {
+	return fmt.Sprintf("%02d_%s", s.Num, s.Name)
+}
+
+// Scenarios lists all scenarios in the order they have to run.
+var Scenarios = []Scenario{
+	{Num: 1, Name: "Create10", Title: "Create 10 entries", Params: Params{"n": 10}, Prepare: createScenario},
+	{Num: 2, Name: "Create100", Title: "Create 100 entries", Params: Params{"n": 100}, Prepare: createScenario},
+	{Num: 3, Name: "Create1000", Title: "Create 1000 entries", Params: Params{"n": 1000}, Prepare: createScenario},
+	{Num: 4, Name: "BulkCreate1000", Title: "Create 1000 entries (bulk)", Params: Params{"n": 1000}, Prepare: bulkCreateScenario},
+	{Num: 5, Name: "BulkCreate10000", Title: "Create 10000 entries (bulk)", Params: Params{"n": 10000}, Prepare: bulkCreateScenario},
+	{Num: 6, Name: "Read10000", Title: "Read 10000 entries (not a query)", Params: Params{"n": 10000}, Depends: "BulkCreate10000", Prepare: readScenario},
+	{Num: 7, Name: "BulkRead10000", Title: "Read 10000 entries (not a query, bulk)", Params: Params{"n": 10000}, Depends: "BulkCreate10000", Prepare: bulkReadScenario},
+	{Num: 8, Name: "Update10000", Title: "Update 10000 entries", Params: Params{"n": 10000}, Depends: "BulkCreate10000", Prepare: updateScenario},
+	{Num: 9, Name: "BulkUpdate10000", Title: "Update 10000 entries (bulk)", Params: Params{"n": 10000}, Depends: "BulkCreate10000", Prepare: bulkUpdateScenario},
+	{Num: 10, Name: "QueryRead10000", Title: "Read 10000 entries (using query)", Params: Params{"n": 10000}, Depends: "BulkCreate10000", Prepare: queryScenario},
+	{Num: 11, Name: "CreateConnectedPairs10", Title: "Create 10 connected pairs", Params: Params{"n": 10}, Prepare: createPairsScenario},
+	{Num: 12, Name: "CreateConnectedPairs100", Title: "Create 100 connected pairs", Params: Params{"n": 100}, Prepare: createPairsScenario},
+	{Num: 13, Name: "CreateConnectedPairs10000", Title: "Create 10000 connected pairs", Params: Params{"n": 10000}, Prepare: createPairsScenario},
+	{Num: 14, Name: "QueryAllConnectedPairs10000", Title: "Query all neighbours in pair", Params: Params{"expected": 10000}, Depends: "CreateConnectedPairs10000", Prepare: queryPairsScenario},
+	{Num: 15, Name: "QueryAllConnectedPairsOneYear10000", Title: "Query all neighbours in pair (within one year)", Params: Params{"year": 2022, "expected": 365}, Depends: "CreateConnectedPairs10000", Prepare: queryPairsInYearScenario},
+	{Num: 16, Name: "CreateChain1x10000", Title: "Create chain with 10000 artifacts", Params: Params{"n": 10000}, Prepare: createChainScenario},
+	{Num: 17, Name: "QueryNeighbourInChain10", Title: "Query 10th artifact in chain", Params: Params{"n": 10}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 18, Name: "QueryNeighbourInChain100", Title: "Query 100th artifact in chain", Params: Params{"n": 100}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 19, Name: "QueryNeighbourInChain1000", Title: "Query 1000th artifact in chain", Params: Params{"n": 1000}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 20, Name: "QueryNeighbourInChain2000", Title: "Query 2000th artifact in chain", Params: Params{"n": 2000}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 21, Name: "QueryNeighbourInChain5000", Title: "Query 5000th artifact in chain", Params: Params{"n": 5000}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 22, Name: "QueryNeighbourInChain7000", Title: "Query 7000th artifact in chain", Params: Params{"n": 7000}, Depends: "CreateChain1x10000", Prepare: queryNeighbourScenario},
+	{Num: 23, Name: "SumChainItems5000", Title: "Sum 5000 `item`s in chain", Params: Params{"n": 5000}, Depends: "CreateChain1x10000", Prepare: sumChainScenario},
+	{Num: 24, Name: "CreateNeighbours100", Title: "Create 100 direct neighbours", Params: Params{"n": 100}, Prepare: createNeighboursScenario},
+	{Num: 25, Name: "CreateNeighbours1000", Title: "Create 1000 direct neighbours", Params: Params{"n": 1000}, Prepare: createNeighboursScenario},
+	{Num: 26, Name: "CreateNeighbours10000", Title: "Create 10000 direct neighbours", Params: Params{"n": 10000}, Prepare: createNeighboursScenario},
+	{Num: 27, Name: "QuerySortedNeighbours10000", Title: "Query all neighbours (sorted by name)", Params: Params{"expected": 9999}, Depends: "CreateNeighbours10000", Prepare: querySortedNeighboursScenario},
+	{Num: 28, Name: "LoadWorkload10000", Title: "Load 10000 entries for the YCSB workloads (bulk)", Params: Params{"n": 10000}, Prepare: bulkCreateScenario},
+	{Num: 29, Name: "WorkloadA10000", Title: "YCSB workload A: 50 % reads, 50 % updates", Params: Params{"n": 10000}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadA)},
+	{Num: 30, Name: "WorkloadB10000", Title: "YCSB workload B: 95 % reads, 5 % updates", Params: Params{"n": 10000}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadB)},
+	{Num: 31, Name: "WorkloadC10000", Title: "YCSB workload C: reads only", Params: Params{"n": 10000}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadC)},
+	{Num: 32, Name: "WorkloadD10000", Title: "YCSB workload D: 95 % reads of the latest, 5 % inserts", Params: Params{"n": 10000}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadD)},
+	{Num: 33, Name: "WorkloadE10000", Title: "YCSB workload E: 95 % short scans, 5 % inserts", Params: Params{"n": 10000, "scan": 100}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadE)},
+	{Num: 34, Name: "WorkloadF10000", Title: "YCSB workload F: 50 % reads, 50 % read-modify-writes", Params: Params{"n": 10000}, Depends: "LoadWorkload10000", Prepare: workloadScenario(workloadF)},
+}
+
+// Bench runs scenarios against one backend and keeps track of the data they create.
+type Bench struct {
+	Backend Backend
+
+	// Number of artifacts and edges stored before the first scenario.
+	StaticArtifactCount int
+	StaticEdgeCount     int
+
+	// Workers run the measured phase of the scenarios supporting it concurrently, every worker has its own
+	// connection (session) to the database. Scenarios run sequentially on Backend if there are none.
+	Workers []Backend
+
+	// Rate issues the operations of the scenarios supporting it at the fixed rate per second by the Workers
+	// (or Backend if there are none) no matter how fast they respond (open-loop). Zero turns it off.
+	Rate float64
+
+	// Oracle is the source of truth the answers of Backend are compared with. It gets the data created by the
+	// scenarios, not the pre-populated ones, so queries over the whole database may differ on a pre-populated
+	// backend. Nothing is compared if it is nil.
+	Oracle Backend
+
+	// BatchSize is the number of entries of one operation of a bulk scenario run by workers, 1000 if zero.
+	BatchSize int
+
+	// Seed of the generated data. Every run of a scenario gets its own generator of a seed derived from Seed,
+	// the scenario and the number of its previous runs, so benches of the same seed generate the same data no
+	// matter which scenarios were run before.
+	Seed int64
+
+	// NewGenerator creates the generator of a scenario run, NewGenerator of the package if nil, its clock
+	// starting after the pre-populated artifacts at a time derived from the seed. It can replace the clock and
+	// the keys.
+	NewGenerator func(seed int64) *Generator
+
+	// Payload shapes the payload of the artifacts created by the scenarios, there is none by default.
+	Payload PayloadConfig
+
+	// Access is the access pattern of the read and update scenarios, sequential by default.
+	Access AccessConfig
+
+	// Keys of the pre-populated artifacts, loaded by the first scenario drawing from them.
+	staticKeys []string
+
+	// Generator of the current scenario run and the number of runs of every scenario.
+	gen  *Generator
+	runs map[string]int
+
+	// Data created by the last independent scenario and used by its dependants.
+	data Graph
+
+	// The last independent scenario and its result.
+	parent    string
+	parentErr error
+}
+
+// NewBench counts the data already present in the backend.
+func NewBench(ctx context.Context, backend Backend) (*Bench, error) {
+
+	artifacts, edges, err := backend.Count(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed counting static data")
+	}
+
+	return &Bench{Backend: backend, StaticArtifactCount: artifacts, StaticEdgeCount: edges}, nil
+}
+
+// Run runs the scenario and returns the durations of its phases. Data of the previous independent scenario
+// are removed before an independent scenario starts.
+func (b *Bench) Run(ctx context.Context, scenario Scenario) (Timing, error) {
+
+	if scenario.Depends == "" {
+		if err := b.cleanup(ctx); err != nil {
+			return Timing{}, err
+		}
+	} else if err := b.checkParent(scenario); err != nil {
+		return Timing{}, err
+	}
+
+	timing, err := b.run(ctx, scenario)
+
+	if scenario.Depends == "" {
+		b.parent = scenario.Name
+		b.parentErr = err
+	}
+
+	if err != nil {
+		return timing, errors.Wrapf(err, "scenario %s failed", scenario.ID())
+	}
+
+	return timing, nil
+}
+
+// run runs and times the phases of the scenario.
+func (b *Bench) run(ctx context.Context, scenario Scenario) (Timing, error) {
+
+	var timing Timing
+
+	b.newScenarioGenerator(scenario)
+
+	start := time.Now()
+	execution, err := scenario.Prepare(ctx, b, scenario.Params)
+	timing.Prepare = time.Since(start)
+	if err != nil {
+		return timing, err
+	}
+
+	start = time.Now()
+	switch {
+	case b.Rate > 0 && execution.Ops != nil:
+		workers := b.Workers
+		if len(workers) == 0 {
+			workers = []Backend{b.Backend}
+		}
+		timing.Workers = len(workers)
+		timing.Rate = b.Rate
+		timing.Latency, timing.Service, err = runOpsAtRate(ctx, workers, execution.Ops, b.Rate)
+	case len(b.Workers) > 0 && execution.Ops != nil:
+		timing.Workers = len(b.Workers)
+		timing.Operations, err = runOps(ctx, b.Workers, execution.Ops)
+	default:
+		err = execution.Measured(ctx)
+	}
+	timing.Measured = time.Since(start)
+	if err != nil {
+		return timing, err
+	}
+
+	if execution.Verify != nil {
+		start = time.Now()
+		err = execution.Verify(ctx)
+		timing.Verify = time.Since(start)
+		if err != nil {
+			return timing, errors.Wrap(err, "failed verification")
+		}
+	}
+
+	if b.Oracle != nil && execution.Check != nil {
+		start = time.Now()
+		err = execution.Check(ctx, b.Oracle)
+		timing.Verify += time.Since(start)
+		if err != nil {
+			return timing, errors.Wrap(err, "failed oracle check")
+		}
+	}
+
+	return timing, nil
+}
+
+func (b *Bench) newScenarioGenerator(scenario Scenario) {
+
+	if b.runs == nil {
+		b.runs = make(map[string]int)
+	}
+
+	newGenerator := b.NewGenerator
+	if newGenerator == nil {
+		newGenerator = func(seed int64) *Generator {
+			g := NewGenerator(seed)
+			g.Clock = StepClock(runEpoch(b.StaticArtifactCount, seed), time.Millisecond)
+			return g
+		}
+	}
+
+	b.gen = newGenerator(DeriveSeed(b.Seed, scenario.Name, b.runs[scenario.Name]))
+	b.gen.Payload = b.Payload
+	b.runs[scenario.Name]++
+}
+
+// Measure runs the scenario warmup times without measuring and then iterations times, and returns the
+// timings of the measured runs. Every run of an independent scenario starts on clean data.
+func (b *Bench) Measure(ctx context.Context, scenario Scenario, warmup, iterations int) ([]Timing, error) {
+
+	for i := 0; i < warmup; i++ {
+		if _, err := b.Run(ctx, scenario); err != nil {
+			return nil, errors.Wrap(err, "failed warming up")
+		}
+	}
+
+	var timings []Timing
+
+	for i := 0; i < iterations; i++ {
+		timing, err := b.Run(ctx, scenario)
+		if err != nil {
+			return timings, err
+		}
+		timings = append(timings, timing)
+	}
+
+	return timings, nil
+}
+
+// Result creates the result of the scenario measured by Measure.
+func (b *Bench) Result(scenario Scenario, timings []Timing, err error) Result {
+	result := NewResult(b.Backend.Name(), scenario, timings, err)
+	result.StaticArtifactCount = b.StaticArtifactCount
+	result.StaticEdgeCount = b.StaticEdgeCount
+	return result
+}
+
+// Close removes all remaining data created by scenarios.
+func (b *Bench) Close(ctx context.Context) error {
+	return b.cleanup(ctx)
+}
+
+// checkParent makes sure the data a dependent scenario works on were created.
+func (b *Bench) checkParent(scenario Scenario) error {
+
+	if b.parent != scenario.Depends {
+		return errors.Wrapf(ErrSkipped, "scenario %s did not run", scenario.Depends)
+	}
+
+	if errors.Is(b.parentErr, ErrNotImplemented) {
+		return errors.Wrapf(ErrNotImplemented, "scenario %s is not implemented", scenario.Depends)
+	}
+
+	if b.parentErr != nil {
+		return errors.Wrapf(ErrSkipped, "scenario %s failed", scenario.Depends)
+	}
+
+	return nil
+}
+
+func (b *Bench) batchSize() int {
+	if b.BatchSize > 0 {
+		return b.BatchSize
+	}
+	return concurrentBatchSize
+}
+
+// accessKeys returns the keys touched by a read or update scenario. The sequential access touches the artifacts
+// created by the parent scenario once each, the other distributions draw n keys from the pre-populated and the
+// created artifacts. static tells whether the keys can include pre-populated artifacts, which the oracle does
+// not know.
+func (b *Bench) accessKeys(ctx context.Context, n int, access AccessConfig) (keys []string, static bool, err error) {
+
+	distribution := KeyDistribution(access.Distribution)
+	if distribution == "" || distribution == KeysSequential {
+		return b.data.ArtifactKeys, false, nil
+	}
+
+	space, static, err := b.keySpace(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b.gen.Keys(space, n, access), static, nil
+}
+
+// keySpace returns the keys of the pre-populated artifacts followed by the keys of the artifacts created by the
+// scenarios, the newest ones. static tells whether there are any pre-populated artifacts.
+func (b *Bench) keySpace(ctx context.Context) (space []string, static bool, err error) {
+
+	if b.staticKeys == nil {
+		stored, err := b.Backend.Keys(ctx)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed listing stored keys")
+		}
+
+		created := make(map[string]bool, len(b.data.ArtifactKeys))
+		for _, key := range b.data.ArtifactKeys {
+			created[key] = true
+		}
+
+		b.staticKeys = make([]string, 0, len(stored))
+		for _, key := range stored {
+			if !created[key] {
+				b.staticKeys = append(b.staticKeys, key)
+			}
+		}
+	}
+
+	space = make([]string, 0, len(b.staticKeys)+len(b.data.ArtifactKeys))
+	space = append(space, b.staticKeys...)
+	space = append(space, b.data.ArtifactKeys...)
+
+	return space, len(b.staticKeys) > 0, nil
+}
+
+func (b *Bench) track(graph Graph) {
+	b.data.ArtifactKeys = append(b.data.ArtifactKeys, graph.ArtifactKeys...)
+	b.data.EdgeKeys = append(b.data.EdgeKeys, graph.EdgeKeys...)
+}
+
+func (b *Bench) cleanup(ctx context.Context) error {
+
+	if b.data.ArtifactKeys == nil && b.data.EdgeKeys == nil {
+		return nil
+	}
+
+	if err := b.Backend.Cleanup(ctx, b.data); err != nil {
+		return errors.Wrap(err, "failed cleaning up")
+	}
+
+	if b.Oracle != nil {
+		if err := b.Oracle.Cleanup(ctx, b.data); err != nil {
+			return errors.Wrap(err, "failed cleaning up oracle")
+		}
+	}
+
+	b.data = Graph{}
+
+	return nil
+}
+
+func (b *Bench) verifyCount(ctx context.Context, artifacts, edges int) error {
+
+	artifactCount, edgeCount, err := b.Backend.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed counting")
+	}
+
+	if artifactCount-b.StaticArtifactCount != artifacts {
+		return errors.Errorf("expected %d new artifacts, got %d", artifacts, artifactCount-b.StaticArtifactCount)
+	}
+
+	if edgeCount-b.StaticEdgeCount != edges {
+		return errors.Errorf("expected %d new edges, got %d", edges, edgeCount-b.StaticEdgeCount)
+	}
+
+	return nil
+}
+
+// checkEqual compares the answer of the backend with the expected answer of the oracle. The error is the one
+// of the oracle query.
+func checkEqual(what string, expected, actual interface{}, err error) error {
+	if err != nil {
+		return errors.Wrap(err, "failed querying oracle")
+	}
+	if expected != actual {
+		return errors.Wrapf(ErrOracleMismatch, "expected %v %s, got %v", expected, what, actual)
+	}
+	return nil
+}
+
+func verifyEqual(what string, expected, actual int) error {
+	if expected != actual {
+		return errors.Errorf("expected %d %s, got %d", expected, what, actual)
+	}
+	return nil
+}
+
+func createScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	artifacts := b.gen.Artifacts(n)
+	b.track(Graph{ArtifactKeys: artifactKeys(artifacts)})
+	return Execution{
+		Measured: func(ctx context.Context) error { return b.Backend.Create(ctx, artifacts) },
+		Ops: singleOps(n, func(ctx context.Context, backend Backend, i int) error {
+			return backend.Create(ctx, artifacts[i:i+1])
+		}),
+		Verify: func(ctx context.Context) error { return b.verifyCount(ctx, n, 0) },
+		Check:  func(ctx context.Context, oracle Backend) error { return oracle.Create(ctx, artifacts) },
+	}, nil
+}
+
+func bulkCreateScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	artifacts := b.gen.Artifacts(n)
+	b.track(Graph{ArtifactKeys: artifactKeys(artifacts)})
+	return Execution{
+		Measured: func(ctx context.Context) error { return b.Backend.BulkCreate(ctx, artifacts) },
+		Ops: batchOps(n, b.batchSize(), func(ctx context.Context, backend Backend, from, to int) error {
+			return backend.BulkCreate(ctx, artifacts[from:to])
+		}),
+		Verify: func(ctx context.Context) error { return b.verifyCount(ctx, n, 0) },
+		Check:  func(ctx context.Context, oracle Backend) error { return oracle.BulkCreate(ctx, artifacts) },
+	}, nil
+}
+
+func readScenario(ctx context.Context, b *Bench, params Params) (Execution, error) {
+	keys, _, err := b.accessKeys(ctx, params["n"], b.Access)
+	if err != nil {
+		return Execution{}, err
+	}
+	return Execution{
+		Measured: func(ctx context.Context) error {
+			for _, key := range keys {
+				if err := b.Backend.Read(ctx, key); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Ops: singleOps(len(keys), func(ctx context.Context, backend Backend, i int) error {
+			return backend.Read(ctx, keys[i])
+		}),
+	}, nil
+}
+
+// The bulk scenarios touch every drawn key once, the databases differ in reading or updating a key repeated
+// within one operation.
+
+func bulkReadScenario(ctx context.Context, b *Bench, params Params) (Execution, error) {
+	var count int64
+	keys, static, err := b.accessKeys(ctx, params["n"], b.Access)
+	if err != nil {
+		return Execution{}, err
+	}
+	keys = distinctKeys(keys)
+	execution := Execution{
+		Measured: func(ctx context.Context) error { return countOp(&count)(b.Backend.BulkRead(ctx, keys)) },
+		Ops: batchOps(len(keys), b.batchSize(), func(ctx context.Context, backend Backend, from, to int) error {
+			return countOp(&count)(backend.BulkRead(ctx, keys[from:to]))
+		}),
+		Verify: func(ctx context.Context) error {
+			return verifyEqual("artifacts read", len(keys), int(atomic.LoadInt64(&count)))
+		},
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.BulkRead(ctx, keys)
+			return checkEqual("artifacts read", expected, int(atomic.LoadInt64(&count)), err)
+		},
+	}
+	return withoutCheck(execution, static), nil
+}
+
+func updateScenario(ctx context.Context, b *Bench, params Params) (Execution, error) {
+	keys, static, err := b.accessKeys(ctx, params["n"], b.Access)
+	if err != nil {
+		return Execution{}, err
+	}
+	artifacts := b.gen.Updates(keys)
+	execution := Execution{
+		Measured: func(ctx context.Context) error {
+			for _, artifact := range artifacts {
+				if err := b.Backend.Update(ctx, artifact); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Ops: singleOps(len(artifacts), func(ctx context.Context, backend Backend, i int) error {
+			return backend.Update(ctx, artifacts[i])
+		}),
+		Check: func(ctx context.Context, oracle Backend) error {
+			for _, artifact := range artifacts {
+				if err := oracle.Update(ctx, artifact); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return withoutCheck(execution, static), nil
+}
+
+func bulkUpdateScenario(ctx context.Context, b *Bench, params Params) (Execution, error) {
+	var count int64
+	keys, static, err := b.accessKeys(ctx, params["n"], b.Access)
+	if err != nil {
+		return Execution{}, err
+	}
+	artifacts := b.gen.Updates(distinctKeys(keys))
+	execution := Execution{
+		Measured: func(ctx context.Context) error { return countOp(&count)(b.Backend.BulkUpdate(ctx, artifacts)) },
+		Ops: batchOps(len(artifacts), b.batchSize(), func(ctx context.Context, backend Backend, from, to int) error {
+			return countOp(&count)(backend.BulkUpdate(ctx, artifacts[from:to]))
+		}),
+		Verify: func(ctx context.Context) error {
+			return verifyEqual("artifacts updated", len(artifacts), int(atomic.LoadInt64(&count)))
+		},
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.BulkUpdate(ctx, artifacts)
+			return checkEqual("artifacts updated", expected, int(atomic.LoadInt64(&count)), err)
+		},
+	}
+	return withoutCheck(execution, static), nil
+}
+
+func queryScenario(ctx context.Context, b *Bench, params Params) (Execution, error) {
+	var count int64
+	keys, static, err := b.accessKeys(ctx, params["n"], b.Access)
+	if err != nil {
+		return Execution{}, err
+	}
+	keys = distinctKeys(keys)
+	execution := Execution{
+		Measured: func(ctx context.Context) error { return countOp(&count)(b.Backend.Query(ctx, keys)) },
+		Ops: batchOps(len(keys), b.batchSize(), func(ctx context.Context, backend Backend, from, to int) error {
+			return countOp(&count)(backend.Query(ctx, keys[from:to]))
+		}),
+		Verify: func(ctx context.Context) error {
+			return verifyEqual("artifacts queried", len(keys), int(atomic.LoadInt64(&count)))
+		},
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.Query(ctx, keys)
+			return checkEqual("artifacts queried", expected, int(atomic.LoadInt64(&count)), err)
+		},
+	}
+	return withoutCheck(execution, static), nil
+}
+
+// withoutCheck drops the oracle check of a scenario touching pre-populated artifacts, the oracle does not hold
+// them.
+func withoutCheck(execution Execution, static bool) Execution {
+	if static {
+		execution.Check = nil
+	}
+	return execution
+}
+
+// countOp adds the number of entries returned by an operation to the count. The count is safe to use by
+// concurrent operations.
+func countOp(count *int64) func(n int, err error) error {
+	return func(n int, err error) error {
+		atomic.AddInt64(count, int64(n))
+		return err
+	}
+}
+
+func createPairsScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	dataset := b.gen.Pairs(n)
+	b.track(dataset.Keys())
+	return Execution{
+		Measured: func(ctx context.Context) error { return b.Backend.CreatePairs(ctx, dataset) },
+		Verify:   func(ctx context.Context) error { return b.verifyCount(ctx, 2*n, n) },
+		Check:    func(ctx context.Context, oracle Backend) error { return oracle.CreatePairs(ctx, dataset) },
+	}, nil
+}
+
+// queryPairsScenario counts the neighbours of all edges, the pre-populated edges included. Neither the expected
+// count nor the oracle knows about them, they are added to both.
+func queryPairsScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	expected := params["expected"] + b.StaticEdgeCount
+	if b.StaticArtifactCount > documentCountNotToCycle {
+		return Execution{}, errors.Wrap(ErrSkipped, "too many documents to cycle over")
+	}
+	var count int
+	return Execution{
+		Measured: func(ctx context.Context) (err error) {
+			count, err = b.Backend.QueryPairs(ctx)
+			return err
+		},
+		Verify: func(ctx context.Context) error { return verifyEqual("neighbours", expected, count) },
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.QueryPairs(ctx)
+			return checkEqual("neighbours", expected+b.StaticEdgeCount, count, err)
+		},
+	}, nil
+}
+
+// queryPairsInYearScenario counts the neighbours created in the year. It is skipped on a database with
+// pre-populated edges, the number of them falling into the year is not known.
+func queryPairsInYearScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	year := params["year"]
+	expected := params["expected"]
+	if b.StaticArtifactCount > documentCountNotToCycle {
+		return Execution{}, errors.Wrap(ErrSkipped, "too many documents to cycle over")
+	}
+	if b.StaticEdgeCount > 0 {
+		return Execution{}, errors.Wrap(ErrSkipped, "pre-populated edges of unknown dates")
+	}
+	var count int
+	return Execution{
+		Measured: func(ctx context.Context) (err error) {
+			count, err = b.Backend.QueryPairsInYear(ctx, year)
+			return err
+		},
+		Verify: func(ctx context.Context) error { return verifyEqual("neighbours", expected, count) },
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.QueryPairsInYear(ctx, year)
+			return checkEqual("neighbours", expected, count, err)
+		},
+	}, nil
+}
+
+func createChainScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	dataset := b.gen.Chain(n)
+	b.track(dataset.Keys())
+	return Execution{
+		Measured: func(ctx context.Context) error { return b.Backend.CreateChain(ctx, dataset) },
+		Verify:   func(ctx context.Context) error { return b.verifyCount(ctx, n, n-1) },
+		Check:    func(ctx context.Context, oracle Backend) error { return oracle.CreateChain(ctx, dataset) },
+	}, nil
+}
+
+func queryNeighbourScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	var artifact Artifact
+	keys := b.data.ArtifactKeys
+	return Execution{
+		Measured: func(ctx context.Context) (err error) {
+			artifact, err = b.Backend.QueryNeighbourN(ctx, keys[0], n)
+			return err
+		},
+		Verify: func(ctx context.Context) error {
+			if artifact.Key != keys[n] {
+				return errors.Errorf("expected artifact %s, got %s", keys[n], artifact.Key)
+			}
+			if !strings.HasSuffix(artifact.Name, fmt.Sprintf("-%d", n)) {
+				return errors.Errorf("unexpected artifact name %s", artifact.Name)
+			}
+			return nil
+		},
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.QueryNeighbourN(ctx, keys[0], n)
+			if err != nil {
+				return err
+			}
+			if err := checkEqual("artifact key", expected.Key, artifact.Key, nil); err != nil {
+				return err
+			}
+			return checkEqual("artifact name", expected.Name, artifact.Name, nil)
+		},
+	}, nil
+}
+
+func sumChainScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	var sum int
+	keys := b.data.ArtifactKeys
+	return Execution{
+		Measured: func(ctx context.Context) (err error) {
+			sum, err = b.Backend.SumNeighbourItems(ctx, keys[0], n-1)
+			return err
+		},
+		Verify: func(ctx context.Context) error { return verifyEqual("as sum", n, sum) },
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.SumNeighbourItems(ctx, keys[0], n-1)
+			return checkEqual("as sum", expected, sum, err)
+		},
+	}, nil
+}
+
+func createNeighboursScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	n := params["n"]
+	dataset := b.gen.Neighbours(n)
+	b.track(dataset.Keys())
+	return Execution{
+		Measured: func(ctx context.Context) error { return b.Backend.CreateNeighbours(ctx, dataset) },
+		Verify:   func(ctx context.Context) error { return b.verifyCount(ctx, n, n-1) },
+		Check:    func(ctx context.Context, oracle Backend) error { return oracle.CreateNeighbours(ctx, dataset) },
+	}, nil
+}
+
+func querySortedNeighboursScenario(_ context.Context, b *Bench, params Params) (Execution, error) {
+	expected := params["expected"]
+	var count int
+	keys := b.data.ArtifactKeys
+	return Execution{
+		Measured: func(ctx context.Context) (err error) {
+			count, err = b.Backend.QuerySortedNeighbours(ctx, keys[0])
+			return err
+		},
+		Verify: func(ctx context.Context) error { return verifyEqual("neighbours", expected, count) },
+		Check: func(ctx context.Context, oracle Backend) error {
+			expected, err := oracle.QuerySortedNeighbours(ctx, keys[0])
+			return checkEqual("neighbours", expected, count, err)
+		},
+	}, nil
+}
+
+// FindScenario returns the scenario by its name, ID or number.
+func FindScenario(name string) (Scenario, bool) {
+	for _, scenario := range Scenarios {
+		if name == scenario.Name || name == scenario.ID() || name == strconv.Itoa(scenario.Num) {
+			return scenario, true
+		}
+	}
+	return Scenario{}, false
+}
+
+// SelectScenarios returns the named scenarios together with the scenarios they depend on, in the order they
+// have to run. All scenarios are returned when no name is given.
+func SelectScenarios(names []string) ([]Scenario, error) {
+
+	if len(names) == 0 {
+		return Scenarios, nil
+	}
+
+	selected := make(map[string]bool)
+
+	for _, name := range names {
+		scenario, ok := FindScenario(name)
+		if !ok {
+			return nil, errors.Errorf("unknown scenario %s", name)
+		}
+
+		selected[scenario.Name] = true
+		if scenario.Depends != "" {
+			selected[scenario.Depends] = true
+		}
+	}
+
+	var scenarios []Scenario
+	for _, scenario := range Scenarios {
+		if selected[scenario.Name] {
+			scenarios = append(scenarios, scenario)
+		}
+	}
+
+	return scenarios, nil
+}
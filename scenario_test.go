@@ -0,0 +1,131 @@
+package db_bench
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig loads the config file given by the DBBENCH_CONFIG environment variable and applies its scenario
+// parameters.
+func testConfig(t *testing.T) Config {
+	config, err := LoadConfig(os.Getenv(ConfigEnv))
+	require.NoError(t, err)
+	config.ConfigureScenarios()
+	return config
+}
+
+// runScenarios runs all scenarios against the backend, each one as a subtest, warmup and iterations times. The
+// answers are checked against the oracle unless it is nil. The scenarios remove only the data they created, a
+// pre-populated database has to keep its entries.
+func runScenarios(t *testing.T, config Config, backend, oracle Backend) {
+
+	ctx := context.Background()
+
+	bench, err := NewBench(ctx, backend)
+	require.NoError(t, err)
+	bench.BatchSize = config.Run.Batch
+	bench.Seed = int64(config.Run.Seed)
+	bench.Payload = config.Payload
+	bench.Access = config.Access
+	bench.Oracle = oracle
+
+	defer func() {
+		require.NoError(t, bench.Close(ctx))
+
+		artifacts, edges, err := backend.Count(ctx)
+		require.NoError(t, err)
+		require.Equal(t, bench.StaticArtifactCount, artifacts, "pre-populated artifacts")
+		require.Equal(t, bench.StaticEdgeCount, edges, "pre-populated edges")
+
+		require.NoError(t, backend.Close())
+	}()
+
+	var results []Result
+
+	for _, scenario := range Scenarios {
+		t.Run(scenario.ID(), func(t *testing.T) {
+			timings, err := bench.Measure(ctx, scenario, config.Run.Warmup, config.Run.Iterations)
+			result := bench.Result(scenario, timings, err)
+			results = append(results, result)
+
+			if result.Status == StatusSkipped || result.Status == StatusNotImplemented {
+				t.Skip(err)
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	printStats(t, backend.Name(), results)
+}
+
+// runQueries checks the answers of the reads by key, the chain and neighbour queries and the names updated in a
+// chain.
+// It removes the created data and closes the backend.
+func runQueries(t *testing.T, backend Backend) {
+
+	ctx := context.Background()
+	g := NewGenerator(time.Now().UnixNano())
+
+	defer func() { require.NoError(t, backend.Close()) }()
+
+	chain := g.Chain(10)
+	require.NoError(t, backend.CreateChain(ctx, chain))
+	defer func() { require.NoError(t, backend.Cleanup(ctx, chain.Keys())) }()
+
+	neighbours := g.Neighbours(5)
+	require.NoError(t, backend.CreateNeighbours(ctx, neighbours))
+	defer func() { require.NoError(t, backend.Cleanup(ctx, neighbours.Keys())) }()
+
+	// The reads by key count only the stored artifacts.
+	keys := append(artifactKeys(chain.Artifacts[:5]), g.Artifacts(1)[0].Key)
+	require.NoError(t, backend.Read(ctx, keys[0]))
+
+	read, err := backend.BulkRead(ctx, keys)
+	require.NoError(t, err)
+	assert.Equal(t, 5, read)
+
+	read, err = backend.Query(ctx, keys)
+	require.NoError(t, err)
+	assert.Equal(t, 5, read)
+
+	neighbour, err := backend.QueryNeighbourN(ctx, chain.Artifacts[0].Key, 3)
+	require.NoError(t, err)
+	assert.Equal(t, chain.Artifacts[3].Key, neighbour.Key)
+	assert.Equal(t, chain.Artifacts[3].Name, neighbour.Name)
+
+	sum, err := backend.SumNeighbourItems(ctx, chain.Artifacts[2].Key, 4)
+	require.NoError(t, err)
+	var expected int
+	for _, artifact := range chain.Artifacts[2:7] {
+		expected += artifact.Item
+	}
+	assert.Equal(t, expected, sum)
+
+	count, err := backend.QuerySortedNeighbours(ctx, neighbours.Artifacts[0].Key)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	// The updates change the name and description only, the items keep their sum.
+	update := g.Updates([]string{chain.Artifacts[3].Key})[0]
+	require.NoError(t, backend.Update(ctx, update))
+
+	updates := g.Updates(artifactKeys(chain.Artifacts[4:6]))
+	updated, err := backend.BulkUpdate(ctx, updates)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	for i, expected := range []Artifact{update, updates[0], updates[1]} {
+		artifact, err := backend.QueryNeighbourN(ctx, chain.Artifacts[0].Key, 3+i)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Name, artifact.Name)
+	}
+
+	sum, err = backend.SumNeighbourItems(ctx, chain.Artifacts[2].Key, 4)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sum)
+}
@@ -0,0 +1,563 @@
+package db_bench
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMaxRows limits the rows of one multi-row insert, SQLite limits the number of bound variables.
+const sqliteMaxRows = 1000
+
+// sqliteTimeLayout is the text format of the timestamps understood by the SQLite date and time functions.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000"
+
+func InitSQLite(path string) (*sql.DB, error) {
+
+	// A long busy timeout lets the concurrent workers wait for the database lock instead of failing.
+	dsn := "file:" + path + "?_pragma=busy_timeout(60000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening sqlite database")
+	}
+
+	return db, nil
+}
+
+// CreateSQLiteTestingTables creates the same tables as CreatePostgresTestingTables.
+func CreateSQLiteTestingTables(db *sql.DB) error {
+
+	artifactSTMT := `CREATE TABLE IF NOT EXISTS artifacts
+(
+    id           TEXT PRIMARY KEY,
+    "name"       TEXT NOT NULL,
+    description  TEXT,
+    item         INTEGER DEFAULT 1,
+    create_time  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    payload      TEXT
+);`
+
+	edgeSTMT := `CREATE TABLE IF NOT EXISTS edges
+(
+    id      TEXT PRIMARY KEY,
+    "from"  TEXT REFERENCES artifacts,
+    "to"    TEXT REFERENCES artifacts,
+    body    TEXT
+);`
+
+	_, err := db.Exec(artifactSTMT)
+	if err != nil {
+		return errors.Wrap(err, "failed creating artifact table")
+	}
+
+	// SQLite has no `ADD COLUMN IF NOT EXISTS`, the payload column is added to older tables if it is missing.
+	var payloadColumns int
+	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('artifacts') WHERE "name" = 'payload';`).Scan(&payloadColumns)
+	if err != nil {
+		return errors.Wrap(err, "failed reading artifact table")
+	}
+
+	if payloadColumns == 0 {
+		_, err = db.Exec(`ALTER TABLE artifacts ADD COLUMN payload TEXT;`)
+		if err != nil {
+			return errors.Wrap(err, "failed adding payload column")
+		}
+	}
+
+	_, err = db.Exec(edgeSTMT)
+	if err != nil {
+		return errors.Wrap(err, "failed creating edge table")
+	}
+
+	return nil
+}
+
+func sqliteTime(tm time.Time) string {
+	return tm.UTC().Format(sqliteTimeLayout)
+}
+
+func createSQLiteArtifacts(db *sql.DB, artifacts []Artifact) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+
+	for _, artifact := range artifacts {
+
+		stmt := `INSERT INTO artifacts(id, "name", description, item, create_time, payload) VALUES (?, ?, ?, ?, ?, ?);`
+
+		_, err := tx.Exec(stmt, artifact.Key, artifact.Name, artifact.Description, artifact.Item, sqliteTime(artifact.CreateTime), encodePayload(artifact.Payload))
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "failed inserting into table")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+// insertSQLiteRows inserts the rows by multi-row statements of sqliteMaxRows rows at most.
+func insertSQLiteRows(tx *sql.Tx, insert string, columns int, rows [][]interface{}) error {
+
+	for start := 0; start < len(rows); start += sqliteMaxRows {
+		end := start + sqliteMaxRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+
+		var stmt strings.Builder
+		var args []interface{}
+
+		stmt.WriteString(insert)
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			stmt.WriteString(placeholder)
+			args = append(args, row...)
+		}
+
+		if _, err := tx.Exec(stmt.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func artifactRows(artifacts []Artifact) [][]interface{} {
+	rows := make([][]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
+		rows[i] = []interface{}{artifact.Key, artifact.Name, artifact.Description, artifact.Item, sqliteTime(artifact.CreateTime), encodePayload(artifact.Payload)}
+	}
+	return rows
+}
+
+func edgeRows(edges []Edge) [][]interface{} {
+	rows := make([][]interface{}, len(edges))
+	for i, edge := range edges {
+		rows[i] = []interface{}{edge.Key, edge.From, edge.To, edge.Body}
+	}
+	return rows
+}
+
+func createBulkSQLiteArtifacts(db *sql.DB, artifacts []Artifact) error {
+	return createSQLiteGraph(db, Dataset{Artifacts: artifacts})
+}
+
+func readOneSQLiteArtifact(db *sql.DB, id string) error {
+
+	var name string
+
+	err := db.QueryRow(`SELECT "name" FROM artifacts WHERE id = ?;`, id).Scan(&name)
+	if err != nil {
+		return errors.Wrap(err, "failed reading entry")
+	}
+
+	return nil
+}
+
+func readBulkSQLiteArtifacts(db *sql.DB, ids []string) (int, error) {
+
+	var count int
+
+	for start := 0; start < len(ids); start += sqliteMaxRows {
+		end := start + sqliteMaxRows
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		args := make([]interface{}, end-start)
+		for i, id := range ids[start:end] {
+			args[i] = id
+		}
+
+		stmt := `SELECT "name" FROM artifacts WHERE id IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + `);`
+
+		n, err := countSQLiteRows(db, stmt, args...)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+func removeBulkSQLiteRows(db *sql.DB, table string, ids []string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE id = ?;", id); err != nil {
+			_ = tx.Rollback()
+			return errors.Wrapf(err, "failed removing from %s", table)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+func updateOneSQLiteArtifact(db *sql.DB, artifact Artifact) error {
+
+	_, err := db.Exec(`UPDATE artifacts SET "name" = ?, description = ? WHERE id = ?;`, artifact.Name, artifact.Description, artifact.Key)
+	if err != nil {
+		return errors.Wrap(err, "failed updating entry")
+	}
+
+	return nil
+}
+
+func updateBulkSQLiteArtifacts(db *sql.DB, artifacts []Artifact) (int, error) {
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed creating transaction")
+	}
+
+	stmt, err := tx.Prepare(`UPDATE artifacts SET "name" = ?, description = ? WHERE id = ?;`)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, errors.Wrap(err, "failed preparing statement")
+	}
+	defer stmt.Close()
+
+	var count int64
+	for _, artifact := range artifacts {
+		result, err := stmt.Exec(artifact.Name, artifact.Description, artifact.Key)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, errors.Wrap(err, "failed updating table")
+		}
+		n, _ := result.RowsAffected()
+		count += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "failed committing transaction")
+	}
+
+	return int(count), nil
+}
+
+func queryReadSQLiteArtifacts(db *sql.DB, ids []string) (int, error) {
+
+	stmt, err := db.Prepare(`SELECT "name" FROM artifacts WHERE id = ?;`)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed preparing statement")
+	}
+	defer stmt.Close()
+
+	var count int
+	for _, id := range ids {
+		var name string
+		err := stmt.QueryRow(id).Scan(&name)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed reading table")
+		}
+		count += 1
+	}
+
+	return count, nil
+}
+
+// createSQLiteGraph creates the artifacts and the edges connecting them in one transaction. It is used for pairs,
+// chains and neighbours.
+func createSQLiteGraph(db *sql.DB, dataset Dataset) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed creating transaction")
+	}
+
+	err = insertSQLiteRows(tx, `INSERT INTO artifacts(id, "name", description, item, create_time, payload) VALUES `, 6, artifactRows(dataset.Artifacts))
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "failed inserting into table")
+	}
+
+	err = insertSQLiteRows(tx, `INSERT INTO edges(id, "from", "to", body) VALUES `, 4, edgeRows(dataset.Edges))
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "failed inserting into table")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed committing transaction")
+	}
+
+	return nil
+}
+
+// countSQLiteRows runs the query and counts the returned rows.
+func countSQLiteRows(db *sql.DB, stmt string, args ...interface{}) (int, error) {
+
+	rows, err := db.Query(stmt, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var name string
+
+		err = rows.Scan(&name)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed scanning variables")
+		}
+		count += 1
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "failed reading rows")
+	}
+
+	return count, nil
+}
+
+func queryAllSQLitePairs(db *sql.DB) (int, error) {
+	stmt := `SELECT t."name" FROM edges INNER JOIN artifacts f ON edges."from" = f.id INNER JOIN artifacts t ON edges."to" = t.id;`
+	return countSQLiteRows(db, stmt)
+}
+
+func queryAllSQLitePairsOneYear(db *sql.DB, year int) (int, error) {
+	stmt := `SELECT t."name" FROM edges INNER JOIN artifacts f ON edges."from" = f.id INNER JOIN artifacts t ON edges."to" = t.id WHERE CAST(strftime('%Y', t.create_time) AS INTEGER) = ?;`
+	return countSQLiteRows(db, stmt, year)
+}
+
+func querySQLiteNeighbourN(db *sql.DB, startingID string, i int) (string, string, error) {
+
+	stmt := `
+WITH RECURSIVE neighbours(id, name, n) as (
+    SELECT id, "name", 0 FROM artifacts WHERE id = ?
+UNION
+    SELECT e."to", a."name", n+1 FROM edges e INNER JOIN neighbours n ON e."from" = n.id INNER JOIN artifacts a ON e."to" = a.id WHERE n < ?
+) SELECT * FROM neighbours LIMIT 1 OFFSET ?;
+`
+
+	var id string
+	var name string
+	var n int
+
+	err := db.QueryRow(stmt, startingID, i, i).Scan(&id, &name, &n)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed searching in chain")
+	}
+
+	return id, name, nil
+}
+
+func sumSQLiteNeighbourNItems(db *sql.DB, startingID string, i int) (int, error) {
+
+	stmt := `
+WITH RECURSIVE neighbours(id, name, item, n) as (
+    SELECT id, "name", item, 0 FROM artifacts WHERE id = ?
+UNION
+    SELECT e."to", a."name", a.item, n+1 FROM edges e INNER JOIN neighbours n ON e."from" = n.id INNER JOIN artifacts a ON e."to" = a.id WHERE n < ?
+) SELECT sum(item) FROM neighbours;
+`
+
+	var sum int
+
+	err := db.QueryRow(stmt, startingID, i).Scan(&sum)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed searching in chain")
+	}
+
+	return sum, nil
+}
+
+func querySQLiteSortedNeighbours(db *sql.DB, id string) (int, error) {
+	stmt := `SELECT a."name" FROM edges e INNER JOIN artifacts a ON e."to" = a.id WHERE e."from" = ? ORDER BY a."name";`
+	return countSQLiteRows(db, stmt, id)
+}
+
+// readSQLiteKeys returns the keys of all artifacts ordered by their creation time.
+func readSQLiteKeys(db *sql.DB) ([]string, error) {
+
+	rows, err := db.Query(`SELECT id FROM artifacts ORDER BY create_time, id;`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading table")
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed scanning variables")
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed reading rows")
+	}
+
+	return keys, nil
+}
+
+func countSQLiteTables(db *sql.DB) (int, int, error) {
+
+	var artifactCounter int
+	var edgeCounter int
+
+	err := db.QueryRow("SELECT COUNT(*) FROM artifacts;").Scan(&artifactCounter)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed counting rows in artifact table")
+	}
+
+	err = db.QueryRow("SELECT COUNT(*) FROM edges;").Scan(&edgeCounter)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed counting rows in edge table")
+	}
+
+	return artifactCounter, edgeCounter, nil
+}
+
+// sqliteBackend adapts the SQLite functions to the Backend interface.
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+// NewSQLiteBackend opens (or creates) the SQLite database file and makes sure the testing tables exist.
+func NewSQLiteBackend(path string) (Backend, error) {
+
+	db, err := InitSQLite(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed initializing sqlite")
+	}
+
+	if err := CreateSQLiteTestingTables(db); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed creating testing tables")
+	}
+
+	return &sqliteBackend{db: db}, nil
+}
+
+func (b *sqliteBackend) Name() string {
+	return "sqlite"
+}
+
+func (b *sqliteBackend) Count(_ context.Context) (int, int, error) {
+	return countSQLiteTables(b.db)
+}
+
+func (b *sqliteBackend) Keys(_ context.Context) ([]string, error) {
+	return readSQLiteKeys(b.db)
+}
+
+func (b *sqliteBackend) Create(_ context.Context, artifacts []Artifact) error {
+	return createSQLiteArtifacts(b.db, artifacts)
+}
+
+func (b *sqliteBackend) BulkCreate(_ context.Context, artifacts []Artifact) error {
+	return createBulkSQLiteArtifacts(b.db, artifacts)
+}
+
+func (b *sqliteBackend) Read(_ context.Context, key string) error {
+	return readOneSQLiteArtifact(b.db, key)
+}
+
+func (b *sqliteBackend) BulkRead(_ context.Context, keys []string) (int, error) {
+	return readBulkSQLiteArtifacts(b.db, keys)
+}
+
+func (b *sqliteBackend) Update(_ context.Context, artifact Artifact) error {
+	return updateOneSQLiteArtifact(b.db, artifact)
+}
+
+func (b *sqliteBackend) BulkUpdate(_ context.Context, artifacts []Artifact) (int, error) {
+	return updateBulkSQLiteArtifacts(b.db, artifacts)
+}
+
+func (b *sqliteBackend) Query(_ context.Context, keys []string) (int, error) {
+	return queryReadSQLiteArtifacts(b.db, keys)
+}
+
+func (b *sqliteBackend) CreatePairs(_ context.Context, dataset Dataset) error {
+	return createSQLiteGraph(b.db, dataset)
+}
+
+func (b *sqliteBackend) QueryPairs(_ context.Context) (int, error) {
+	return queryAllSQLitePairs(b.db)
+}
+
+func (b *sqliteBackend) QueryPairsInYear(_ context.Context, year int) (int, error) {
+	return queryAllSQLitePairsOneYear(b.db, year)
+}
+
+func (b *sqliteBackend) CreateChain(_ context.Context, dataset Dataset) error {
+	return createSQLiteGraph(b.db, dataset)
+}
+
+func (b *sqliteBackend) QueryNeighbourN(_ context.Context, key string, n int) (Artifact, error) {
+
+	id, name, err := querySQLiteNeighbourN(b.db, key, n)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{Key: id, Name: name}, nil
+}
+
+func (b *sqliteBackend) SumNeighbourItems(_ context.Context, key string, n int) (int, error) {
+	return sumSQLiteNeighbourNItems(b.db, key, n)
+}
+
+func (b *sqliteBackend) CreateNeighbours(_ context.Context, dataset Dataset) error {
+	return createSQLiteGraph(b.db, dataset)
+}
+
+func (b *sqliteBackend) QuerySortedNeighbours(_ context.Context, key string) (int, error) {
+	return querySQLiteSortedNeighbours(b.db, key)
+}
+
+func (b *sqliteBackend) Cleanup(_ context.Context, graph Graph) error {
+
+	if graph.EdgeKeys != nil {
+		if err := removeBulkSQLiteRows(b.db, "edges", graph.EdgeKeys); err != nil {
+			return err
+		}
+	}
+
+	if graph.ArtifactKeys != nil {
+		if err := removeBulkSQLiteRows(b.db, "artifacts", graph.ArtifactKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
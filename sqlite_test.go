@@ -0,0 +1,27 @@
+package db_bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteSuite(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewSQLiteBackend(config.SQLite.Path)
+	require.NoError(t, err)
+
+	runScenarios(t, config, backend, NewMemoryBackend())
+}
+
+func TestSQLiteQueries(t *testing.T) {
+
+	config := testConfig(t)
+
+	backend, err := NewSQLiteBackend(config.SQLite.Path)
+	require.NoError(t, err)
+
+	runQueries(t, backend)
+}
@@ -0,0 +1,75 @@
+package db_bench
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Stats summarizes the durations of repeated scenario runs.
+type Stats struct {
+	Count  int           `json:"count"`
+	Min    time.Duration `json:"min"`
+	Mean   time.Duration `json:"mean"`
+	Median time.Duration `json:"median"`
+	P90    time.Duration `json:"p90"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+	StdDev time.Duration `json:"stddev"`
+}
+
+// ComputeStats computes the statistics of the samples. Percentiles are interpolated linearly between the
+// closest ranks.
+func ComputeStats(samples []time.Duration) Stats {
+
+	if len(samples) == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum float64
+	for _, sample := range sorted {
+		sum += float64(sample)
+	}
+	mean := sum / float64(len(sorted))
+
+	var squares float64
+	for _, sample := range sorted {
+		squares += (float64(sample) - mean) * (float64(sample) - mean)
+	}
+
+	// Sample standard deviation, a single sample has none.
+	var stdDev float64
+	if len(sorted) > 1 {
+		stdDev = math.Sqrt(squares / float64(len(sorted)-1))
+	}
+
+	return Stats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Mean:   time.Duration(mean),
+		Median: percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+		P99:    percentile(sorted, 99),
+		Max:    sorted[len(sorted)-1],
+		StdDev: time.Duration(stdDev),
+	}
+}
+
+// percentile returns the p-th percentile of sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	weight := rank - float64(lower)
+	return sorted[lower] + time.Duration(math.Round(weight*float64(sorted[upper]-sorted[lower])))
+}
@@ -0,0 +1,41 @@
+package db_bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStats(t *testing.T) {
+
+	var samples []time.Duration
+	for i := 10; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := ComputeStats(samples)
+
+	assert.Equal(t, 10, stats.Count)
+	assert.Equal(t, 1*time.Millisecond, stats.Min)
+	assert.Equal(t, 10*time.Millisecond, stats.Max)
+	assert.Equal(t, 5500*time.Microsecond, stats.Mean)
+	assert.Equal(t, 5500*time.Microsecond, stats.Median)
+	assert.Equal(t, 9100*time.Microsecond, stats.P90)
+	assert.Equal(t, 9910*time.Microsecond, stats.P99)
+	assert.InDelta(t, float64(3027650*time.Nanosecond), float64(stats.StdDev), float64(time.Microsecond))
+}
+
+func TestComputeStatsSingleSample(t *testing.T) {
+
+	stats := ComputeStats([]time.Duration{time.Second})
+
+	assert.Equal(t, time.Second, stats.Min)
+	assert.Equal(t, time.Second, stats.Median)
+	assert.Equal(t, time.Second, stats.P99)
+	assert.Equal(t, time.Duration(0), stats.StdDev)
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	assert.Equal(t, Stats{}, ComputeStats(nil))
+}
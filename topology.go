@@ -0,0 +1,201 @@
+package db_bench
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Topology is the shape of the graph generated by TopologyGenerator.
+type Topology string
+
+const (
+	// TopologyNone generates isolated artifacts without edges.
+	TopologyNone Topology = "none"
+
+	// TopologyRandom connects uniformly random pairs of artifacts.
+	TopologyRandom Topology = "random"
+
+	// TopologyPreferential connects every new artifact to artifacts chosen in proportion to their degree
+	// (Barabási–Albert), so a few hubs collect most of the edges.
+	TopologyPreferential Topology = "preferential"
+
+	// TopologyForest grows trees, every edge connects a new artifact to a random artifact of the current tree.
+	TopologyForest Topology = "forest"
+
+	// TopologyChains grows chains, every edge connects the previous artifact to the new one.
+	TopologyChains Topology = "chains"
+)
+
+// Topologies lists all topologies.
+var Topologies = []Topology{TopologyNone, TopologyRandom, TopologyPreferential, TopologyForest, TopologyChains}
+
+func (t Topology) valid() bool {
+	for _, topology := range Topologies {
+		if t == topology {
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyGenerator generates a graph of n artifacts and the given number of edges in chunks, so a large graph
+// can be written chunk by chunk. The edges are spread evenly over the artifacts and connect every new artifact to
+// the artifacts generated before, possibly in the previous chunks. Only the keys the topology may still connect to
+// are kept: none for isolated artifacts, the previous one for chains and the current tree for forests. The random
+// and preferential topologies keep the keys of all artifacts (the latter their degrees too), their memory grows
+// with the graph.
+type TopologyGenerator struct {
+	gen      *Generator
+	topology Topology
+	n        int
+	edges    int
+
+	// keys of the artifacts the topology may still connect to, from the artifact of index first on.
+	keys  []string
+	first int
+
+	// endpoints holds the index of every artifact once and once more per its edge, a uniform choice of it is a
+	// choice in proportion to the degree.
+	endpoints []int
+
+	// pending edges are due to the first artifact, they wait for a second one to connect to.
+	pending int
+
+	artifactCount int
+	edgeCount     int
+}
+
+// NewTopologyGenerator checks that the topology can have that many edges on n artifacts. Trees and chains need
+// fewer edges than artifacts, every artifact without an edge starts a new tree (chain). The artifacts, the keys
+// and the random choices come from the generator.
+func NewTopologyGenerator(gen *Generator, topology Topology, n, edges int) (*TopologyGenerator, error) {
+
+	if !topology.valid() {
+		return nil, errors.Errorf("unknown topology %s", topology)
+	}
+
+	if n < 0 || edges < 0 {
+		return nil, errors.New("number of artifacts and edges can not be negative")
+	}
+
+	switch topology {
+	case TopologyNone:
+		edges = 0
+	case TopologyForest, TopologyChains:
+		if edges >= n && n > 0 {
+			return nil, errors.Errorf("%s of %d artifacts can have at most %d edges", topology, n, n-1)
+		}
+	}
+
+	return &TopologyGenerator{gen: gen, topology: topology, n: n, edges: edges}, nil
+}
+
+// Generated returns the number of artifacts and edges generated so far.
+func (g *TopologyGenerator) Generated() (int, int) {
+	return g.artifactCount, g.edgeCount
+}
+
+// Next generates the next count artifacts, at most up to n, and their share of the edges.
+func (g *TopologyGenerator) Next(count int) Dataset {
+
+	if remaining := g.n - g.artifactCount; count > remaining {
+		count = remaining
+	}
+
+	var dataset Dataset
+
+	for c := 0; c < count; c++ {
+		i := g.artifactCount
+		g.artifactCount++
+
+		artifact := g.gen.artifact("artifact", i, g.gen.Clock())
+		dataset.Artifacts = append(dataset.Artifacts, artifact)
+
+		// The edges due to the artifact, the first i+1 artifacts have edges*(i+1)/n edges in total.
+		due := g.edges*(i+1)/g.n - g.edges*i/g.n
+
+		g.keep(i, artifact.Key, due)
+
+		dataset.Edges = append(dataset.Edges, g.connect(i, due)...)
+	}
+
+	return dataset
+}
+
+// keep keeps the key of the new artifact i if the topology may connect to it, and drops the keys it will not
+// connect to any more.
+func (g *TopologyGenerator) keep(i int, key string, due int) {
+	switch g.topology {
+	case TopologyNone:
+		return
+	case TopologyChains:
+		if len(g.keys) > 1 {
+			g.keys[0], g.keys = g.keys[1], g.keys[:1]
+			g.first++
+		}
+	case TopologyForest:
+		// An artifact without an edge is the root of a new tree.
+		if due == 0 {
+			g.keys, g.first = g.keys[:0], i
+		}
+	}
+	g.keys = append(g.keys, key)
+}
+
+// connect generates the edges of the new artifact i.
+func (g *TopologyGenerator) connect(i, due int) []Edge {
+
+	var edges []Edge
+
+	switch g.topology {
+	case TopologyRandom:
+		if i == 0 {
+			g.pending = due
+			break
+		}
+		due, g.pending = due+g.pending, 0
+		for e := 0; e < due; e++ {
+			from := g.gen.intn(i + 1)
+			to := (from + 1 + g.gen.intn(i)) % (i + 1)
+			edges = append(edges, g.newEdge(from, to))
+		}
+
+	case TopologyPreferential:
+		if i == 0 {
+			g.pending = due
+			g.endpoints = append(g.endpoints, i)
+			break
+		}
+		due, g.pending = due+g.pending, 0
+		// Targets are chosen among the older artifacts, the new one joins the choices after its edges.
+		for e := 0; e < due; e++ {
+			to := g.endpoints[g.gen.intn(len(g.endpoints))]
+			edges = append(edges, g.newEdge(i, to))
+			g.endpoints = append(g.endpoints, to)
+		}
+		for range edges {
+			g.endpoints = append(g.endpoints, i)
+		}
+		g.endpoints = append(g.endpoints, i)
+
+	case TopologyForest:
+		if due == 0 {
+			break
+		}
+		parent := g.first + g.gen.intn(i-g.first)
+		edges = append(edges, g.newEdge(parent, i))
+
+	case TopologyChains:
+		if due == 0 {
+			break
+		}
+		edges = append(edges, g.newEdge(i-1, i))
+	}
+
+	return edges
+}
+
+func (g *TopologyGenerator) newEdge(from, to int) Edge {
+	edge := g.gen.edge(g.keys[from-g.first], g.keys[to-g.first], g.edgeCount)
+	g.edgeCount += 1
+	return edge
+}
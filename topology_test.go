@@ -0,0 +1,141 @@
+package db_bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateTopology generates the whole graph in chunks and checks that every edge connects artifacts generated
+// by the same or an earlier chunk, as a database with foreign keys requires.
+func generateTopology(t *testing.T, topology Topology, n, edges, chunk int) Dataset {
+
+	generator, err := NewTopologyGenerator(NewGenerator(1), topology, n, edges)
+	require.NoError(t, err)
+
+	var graph Dataset
+	generated := map[string]bool{}
+
+	for {
+		dataset := generator.Next(chunk)
+		if len(dataset.Artifacts) == 0 {
+			break
+		}
+		for _, artifact := range dataset.Artifacts {
+			generated[artifact.Key] = true
+		}
+		for _, edge := range dataset.Edges {
+			require.True(t, generated[edge.From], "edge from an unknown artifact")
+			require.True(t, generated[edge.To], "edge to an unknown artifact")
+		}
+		graph.Artifacts = append(graph.Artifacts, dataset.Artifacts...)
+		graph.Edges = append(graph.Edges, dataset.Edges...)
+	}
+
+	actualN, actualEdges := generator.Generated()
+	assert.Equal(t, n, actualN)
+	assert.Equal(t, len(graph.Edges), actualEdges)
+
+	return graph
+}
+
+func TestTopologyEdgeCount(t *testing.T) {
+
+	for _, topology := range Topologies {
+		t.Run(string(topology), func(t *testing.T) {
+			graph := generateTopology(t, topology, 1000, 900, 128)
+
+			assert.Len(t, graph.Artifacts, 1000)
+			if topology == TopologyNone {
+				assert.Empty(t, graph.Edges)
+			} else {
+				assert.Len(t, graph.Edges, 900)
+			}
+
+			for _, edge := range graph.Edges {
+				assert.NotEqual(t, edge.From, edge.To, "self loop")
+			}
+		})
+	}
+}
+
+func TestTopologyDense(t *testing.T) {
+
+	for _, topology := range []Topology{TopologyRandom, TopologyPreferential} {
+		graph := generateTopology(t, topology, 100, 1000, 7)
+		assert.Len(t, graph.Edges, 1000, topology)
+	}
+
+	_, err := NewTopologyGenerator(NewGenerator(1), TopologyForest, 100, 100)
+	assert.Error(t, err)
+
+	_, err = NewTopologyGenerator(NewGenerator(1), TopologyChains, 100, 100)
+	assert.Error(t, err)
+
+	_, err = NewTopologyGenerator(NewGenerator(1), "star", 100, 10)
+	assert.Error(t, err)
+}
+
+func TestTopologyForest(t *testing.T) {
+
+	graph := generateTopology(t, TopologyForest, 1000, 990, 100)
+
+	parents := map[string]int{}
+	for _, edge := range graph.Edges {
+		parents[edge.To] += 1
+	}
+
+	// Every artifact has at most one parent, the remaining ones are the roots of 10 trees.
+	for _, count := range parents {
+		assert.Equal(t, 1, count)
+	}
+	assert.Equal(t, 10, len(graph.Artifacts)-len(parents))
+}
+
+func TestTopologyChains(t *testing.T) {
+
+	graph := generateTopology(t, TopologyChains, 100, 90, 33)
+
+	index := map[string]int{}
+	for i, artifact := range graph.Artifacts {
+		index[artifact.Key] = i
+	}
+
+	for _, edge := range graph.Edges {
+		assert.Equal(t, index[edge.From]+1, index[edge.To])
+	}
+}
+
+func TestTopologyPreferential(t *testing.T) {
+
+	graph := generateTopology(t, TopologyPreferential, 10000, 30000, 1000)
+
+	degrees := map[string]int{}
+	for _, edge := range graph.Edges {
+		degrees[edge.From] += 1
+		degrees[edge.To] += 1
+	}
+
+	var max int
+	for _, degree := range degrees {
+		if degree > max {
+			max = degree
+		}
+	}
+
+	// The average degree is 6, the hubs of a scale-free graph have far more.
+	assert.Greater(t, max, 60)
+}
+
+func TestTopologyKeptKeys(t *testing.T) {
+
+	for topology, kept := range map[Topology]int{TopologyNone: 0, TopologyChains: 2, TopologyForest: 10, TopologyRandom: 1000} {
+		generator, err := NewTopologyGenerator(NewGenerator(1), topology, 1000, 900)
+		require.NoError(t, err)
+
+		for len(generator.Next(100).Artifacts) > 0 {
+			assert.LessOrEqual(t, len(generator.keys), kept, topology)
+		}
+	}
+}
@@ -1,32 +1,23 @@
 package db_bench
 
 import (
-	"github.com/stretchr/testify/suite"
-	"sort"
+	"strings"
 	"testing"
 )
 
-func printStats(t *testing.T, suiteName string, stats *suite.SuiteInformation) {
+func printStats(t *testing.T, suiteName string, results []Result) {
 
 	t.Logf("=== %s", suiteName)
 
-	keys := make([]string, 0, len(stats.TestStats))
-	for k := range stats.TestStats {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
 	var total float64
 
-	for _, k := range keys {
-		stat := stats.TestStats[k]
-		duration := stat.End.Sub(stat.Start)
-		if stat.Passed && duration.Milliseconds() == 0 {
-			t.Logf("%s: SKIPPED", k)
+	for _, result := range results {
+		if result.Status != StatusOK {
+			t.Logf("Test%s: %s", result.ID(), strings.ToUpper(string(result.Status)))
 		} else {
-			t.Logf("%s: %d ms (%.3f s)", k, duration.Milliseconds(), duration.Seconds())
+			t.Logf("Test%s: %d ms (%.3f s)", result.ID(), result.Stats.Median.Milliseconds(), result.Stats.Median.Seconds())
 		}
-		total += duration.Seconds()
+		total += result.Stats.Median.Seconds()
 	}
 
 	t.Logf("total: %.3f s", total)
@@ -0,0 +1,247 @@
+package db_bench
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// workloadOp is the type of one operation of a mixed workload.
+type workloadOp int
+
+const (
+	workloadRead workloadOp = iota
+	workloadUpdate
+	workloadInsert
+	workloadScan
+	workloadReadModifyWrite
+)
+
+// workload is a mix of operations of the YCSB core workloads, the percentages of the operation types sum to 100.
+// The keys of the reads, updates and scans are drawn by the distribution from all stored artifacts, the inserts
+// create new ones.
+type workload struct {
+	read            int
+	update          int
+	insert          int
+	scan            int
+	readModifyWrite int
+
+	distribution KeyDistribution
+}
+
+// The core workloads of YCSB (https://github.com/brianfrankcooper/YCSB/wiki/Core-Workloads).
+var (
+	// workloadA is update heavy, e.g. a session store recording recent actions.
+	workloadA = workload{read: 50, update: 50, distribution: KeysZipfian}
+
+	// workloadB is read mostly, e.g. photo tagging.
+	workloadB = workload{read: 95, update: 5, distribution: KeysZipfian}
+
+	// workloadC is read only, e.g. a user profile cache.
+	workloadC = workload{read: 100, distribution: KeysZipfian}
+
+	// workloadD reads the latest artifacts, e.g. user status updates.
+	workloadD = workload{read: 95, insert: 5, distribution: KeysLatest}
+
+	// workloadE scans short ranges, e.g. threaded conversations.
+	workloadE = workload{scan: 95, insert: 5, distribution: KeysZipfian}
+
+	// workloadF reads and modifies the artifacts, e.g. a user database.
+	workloadF = workload{read: 50, readModifyWrite: 50, distribution: KeysZipfian}
+)
+
+// draw returns the type of an operation by the percentage p from [0, 100).
+func (w workload) draw(p int) workloadOp {
+	for _, share := range []struct {
+		op      workloadOp
+		percent int
+	}{
+		{workloadRead, w.read},
+		{workloadUpdate, w.update},
+		{workloadInsert, w.insert},
+		{workloadScan, w.scan},
+	} {
+		if p < share.percent {
+			return share.op
+		}
+		p -= share.percent
+	}
+	return workloadReadModifyWrite
+}
+
+// workloadScenario runs n operations of the workload mixed at random, as one operation each. The reads and
+// updates go through the single Read and Update of the backend, a read-modify-write reads and then updates the
+// same artifact. A scan reads up to `scan` artifacts following the drawn one in the order of creation by one
+// BulkRead, the scan lengths are uniform. The access pattern takes the Zipfian constant from Bench.Access.
+//
+// The keys are drawn from the space growing by the inserts as in YCSB, so the latest distribution of workload D
+// favours the artifacts inserted during the run. An operation on an inserted artifact waits for its insert, the
+// concurrent workers may run them out of order.
+func workloadScenario(w workload) PrepareFunc {
+	return func(ctx context.Context, b *Bench, params Params) (Execution, error) {
+
+		n := params["n"]
+
+		stored, static, err := b.keySpace(ctx)
+		if err != nil {
+			return Execution{}, err
+		}
+		if len(stored) == 0 {
+			return Execution{}, errors.Wrap(ErrSkipped, "no artifacts to work on")
+		}
+
+		ops := make([]workloadOp, n)
+		var inserted int
+		for i := range ops {
+			ops[i] = w.draw(b.gen.intn(100))
+			if ops[i] == workloadInsert {
+				inserted++
+			}
+		}
+
+		created := b.gen.Artifacts(inserted)
+		b.track(Graph{ArtifactKeys: artifactKeys(created)})
+
+		// The inserted artifacts follow the stored ones in the key space.
+		space := append(append(make([]string, 0, len(stored)+inserted), stored...), artifactKeys(created)...)
+
+		access := b.Access
+		access.Distribution = string(w.distribution)
+		position := b.gen.positioner(access)
+
+		scanLength := params["scan"]
+		if scanLength < 1 {
+			scanLength = 1
+		}
+
+		// Every operation gets the data of its type, the other slices stay empty at its index. The operations on
+		// inserted artifacts wait for the inserts from..to of the created ones.
+		keys := make([]string, n)
+		updates := make([]Artifact, n)
+		inserts := make([]int, n)
+		scans := make([][]string, n)
+		awaits := make([][2]int, n)
+
+		size := len(stored)
+		var scanned int
+		for i, op := range ops {
+			if op == workloadInsert {
+				inserts[i] = size - len(stored)
+				size++
+				continue
+			}
+
+			start := position(size)
+			end := start + 1
+			if op == workloadScan {
+				end = start + 1 + b.gen.intn(scanLength)
+				if end > size {
+					end = size
+				}
+				scans[i] = space[start:end]
+				scanned += len(scans[i])
+			} else {
+				keys[i] = space[start]
+			}
+
+			if end > len(stored) {
+				from := start - len(stored)
+				if from < 0 {
+					from = 0
+				}
+				awaits[i] = [2]int{from, end - len(stored)}
+			}
+
+			if op == workloadUpdate || op == workloadReadModifyWrite {
+				updates[i] = b.gen.Updates(keys[i : i+1])[0]
+			}
+		}
+
+		// The inserts are acknowledged by closing their channel once, the oracle replays them after the run.
+		acknowledged := make([]chan struct{}, inserted)
+		acknowledge := make([]sync.Once, inserted)
+		for i := range acknowledged {
+			acknowledged[i] = make(chan struct{})
+		}
+
+		await := func(ctx context.Context, i int) error {
+			for j := awaits[i][0]; j < awaits[i][1]; j++ {
+				select {
+				case <-acknowledged[j]:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}
+
+		var count int64
+
+		run := func(ctx context.Context, backend Backend, i int) error {
+
+			if ops[i] == workloadInsert {
+				j := inserts[i]
+				if err := backend.Create(ctx, created[j:j+1]); err != nil {
+					return err
+				}
+				acknowledge[j].Do(func() { close(acknowledged[j]) })
+				return nil
+			}
+
+			if err := await(ctx, i); err != nil {
+				return err
+			}
+
+			switch ops[i] {
+			case workloadRead:
+				return backend.Read(ctx, keys[i])
+			case workloadUpdate:
+				return backend.Update(ctx, updates[i])
+			case workloadScan:
+				return countOp(&count)(backend.BulkRead(ctx, scans[i]))
+			default:
+				if err := backend.Read(ctx, keys[i]); err != nil {
+					return err
+				}
+				return backend.Update(ctx, updates[i])
+			}
+		}
+		execution := Execution{
+			Measured: func(ctx context.Context) error {
+				for i := range ops {
+					if err := run(ctx, b.Backend, i); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Ops: singleOps(n, run),
+			Verify: func(ctx context.Context) error {
+				if err := verifyEqual("artifacts scanned", scanned, int(atomic.LoadInt64(&count))); err != nil {
+					return err
+				}
+				return b.verifyCount(ctx, len(b.data.ArtifactKeys), len(b.data.EdgeKeys))
+			},
+			Check: func(ctx context.Context, oracle Backend) error {
+				var expected int64
+				for i, op := range ops {
+					if op == workloadScan {
+						if err := countOp(&expected)(oracle.BulkRead(ctx, scans[i])); err != nil {
+							return err
+						}
+						continue
+					}
+					if err := run(ctx, oracle, i); err != nil {
+						return err
+					}
+				}
+				return checkEqual("artifacts scanned", int(expected), int(atomic.LoadInt64(&count)), nil)
+			},
+		}
+
+		return withoutCheck(execution, static), nil
+	}
+}
@@ -0,0 +1,103 @@
+package db_bench
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadDraw(t *testing.T) {
+
+	for _, w := range []workload{workloadA, workloadB, workloadC, workloadD, workloadE, workloadF} {
+		counts := make([]int, workloadReadModifyWrite+1)
+		for p := 0; p < 100; p++ {
+			counts[w.draw(p)]++
+		}
+		assert.Equal(t, []int{w.read, w.update, w.insert, w.scan, w.readModifyWrite}, counts)
+	}
+}
+
+// TestWorkloadWorkers runs the workloads by concurrent workers on a pre-populated backend, the inserted
+// artifacts are removed and the pre-populated ones kept.
+func TestWorkloadWorkers(t *testing.T) {
+
+	ctx := context.Background()
+
+	backend := NewMemoryBackend()
+	require.NoError(t, backend.BulkCreate(ctx, NewGenerator(2).Artifacts(1000)))
+
+	bench, err := NewBench(ctx, backend)
+	require.NoError(t, err)
+	bench.Workers = []Backend{backend, backend, backend, backend}
+	bench.Access = DefaultConfig().Access
+
+	for _, scenario := range Scenarios {
+		if scenario.Name != "LoadWorkload10000" && scenario.Depends != "LoadWorkload10000" {
+			continue
+		}
+		_, err := bench.Run(ctx, scenario)
+		require.NoError(t, err, scenario.Name)
+	}
+
+	require.NoError(t, bench.Close(ctx))
+
+	artifacts, _, err := backend.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, artifacts)
+}
+
+// readsBackend records the keys read by the single reads.
+type readsBackend struct {
+	Backend
+
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (b *readsBackend) Read(ctx context.Context, key string) error {
+	b.mu.Lock()
+	b.keys[key] = true
+	b.mu.Unlock()
+	return b.Backend.Read(ctx, key)
+}
+
+// TestWorkloadLatest runs workload D by concurrent workers, its reads favour the artifacts it inserts.
+func TestWorkloadLatest(t *testing.T) {
+
+	ctx := context.Background()
+
+	backend := &readsBackend{Backend: NewMemoryBackend(), keys: make(map[string]bool)}
+
+	bench, err := NewBench(ctx, backend)
+	require.NoError(t, err)
+	bench.Workers = []Backend{backend, backend, backend, backend}
+	bench.Access = DefaultConfig().Access
+
+	load, _ := FindScenario("LoadWorkload10000")
+	_, err = bench.Run(ctx, load)
+	require.NoError(t, err)
+
+	loaded, err := backend.Keys(ctx)
+	require.NoError(t, err)
+
+	workload, _ := FindScenario("WorkloadD10000")
+	_, err = bench.Run(ctx, workload)
+	require.NoError(t, err)
+
+	stored, err := backend.Keys(ctx)
+	require.NoError(t, err)
+	require.Greater(t, len(stored), len(loaded))
+
+	var read int
+	for _, key := range stored[len(loaded):] {
+		if backend.keys[key] {
+			read++
+		}
+	}
+	assert.Greater(t, read, 0)
+
+	require.NoError(t, bench.Close(ctx))
+}